@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/config"
+)
+
+type ctxKey string
+
+const (
+	PrincipalKey ctxKey = "principal"
+)
+
+var (
+	ErrMissingToken  = errors.New("missing authentication token")
+	ErrInvalidToken  = errors.New("invalid authentication token")
+	ErrExpiredToken  = errors.New("authentication token expired")
+	ErrUnknownKey    = errors.New("unknown key id")
+	ErrMissingNonce  = errors.New("missing request nonce")
+	ErrReplayedToken = errors.New("authentication token already used")
+)
+
+type Principal struct {
+	KeyID string
+}
+
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+func New(cfg config.AuthConfig) (Authenticator, error) {
+	switch cfg.Mode {
+	case config.AuthModeNone:
+		return anonymous{}, nil
+	case config.AuthModeHMAC:
+		var nonces NonceStore
+		if cfg.HMACReplayProtection {
+			nonces = NewReplayCache()
+		}
+		return NewHMACAuthenticator(cfg.HMACKeys, cfg.HMACMaxTTL, nonces), nil
+	default:
+		return nil, fmt.Errorf("unsupported auth mode %q", cfg.Mode)
+	}
+}
+
+func Middleware(a Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := a.Authenticate(r)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), PrincipalKey, principal)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func GetPrincipal(ctx context.Context) *Principal {
+	if p, ok := ctx.Value(PrincipalKey).(*Principal); ok {
+		return p
+	}
+
+	return nil
+}
+
+type anonymous struct{}
+
+func (anonymous) Authenticate(r *http.Request) (*Principal, error) {
+	return &Principal{}, nil
+}
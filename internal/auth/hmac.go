@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const hmacScheme = "HMAC"
+
+type HMACAuthenticator struct {
+	keys   map[string][]byte
+	maxTTL time.Duration
+	nonces NonceStore
+}
+
+func NewHMACAuthenticator(keys map[string]string, maxTTL time.Duration, nonces NonceStore) *HMACAuthenticator {
+	secrets := make(map[string][]byte, len(keys))
+	for id, secret := range keys {
+		secrets[id] = []byte(secret)
+	}
+
+	return &HMACAuthenticator{keys: secrets, maxTTL: maxTTL, nonces: nonces}
+}
+
+func (a *HMACAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token, err := parseHMACToken(r.Header.Get("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+
+	secret, ok := a.keys[token.keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	now := time.Now()
+	if now.After(token.expires) {
+		return nil, ErrExpiredToken
+	}
+	if a.maxTTL > 0 && token.expires.Sub(now) > a.maxTTL {
+		return nil, ErrInvalidToken
+	}
+
+	expected := signature(secret, r.Method, r.URL.RequestURI(), token.nonce, token.expires)
+	if !hmac.Equal(expected, token.signature) {
+		return nil, ErrInvalidToken
+	}
+
+	if a.nonces != nil {
+		if token.nonce == "" {
+			return nil, ErrMissingNonce
+		}
+		if a.nonces.Seen(token.keyID+":"+token.nonce, token.expires) {
+			return nil, ErrReplayedToken
+		}
+	}
+
+	return &Principal{KeyID: token.keyID}, nil
+}
+
+func Sign(keyID, secret, method, path, nonce string, expires time.Time) string {
+	sig := signature([]byte(secret), method, path, nonce, expires)
+
+	header := fmt.Sprintf("%s keyId=%s,expires=%d", hmacScheme, keyID, expires.Unix())
+	if nonce != "" {
+		header += ",nonce=" + nonce
+	}
+
+	return header + ",signature=" + hex.EncodeToString(sig)
+}
+
+type hmacToken struct {
+	keyID     string
+	expires   time.Time
+	nonce     string
+	signature []byte
+}
+
+func parseHMACToken(header string) (*hmacToken, error) {
+	if header == "" {
+		return nil, ErrMissingToken
+	}
+
+	scheme, params, ok := strings.Cut(header, " ")
+	if !ok || scheme != hmacScheme {
+		return nil, ErrInvalidToken
+	}
+
+	token := &hmacToken{}
+	for _, part := range strings.Split(params, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		switch key {
+		case "keyId":
+			token.keyID = value
+		case "expires":
+			unix, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, ErrInvalidToken
+			}
+			token.expires = time.Unix(unix, 0)
+		case "nonce":
+			token.nonce = value
+		case "signature":
+			sig, err := hex.DecodeString(value)
+			if err != nil {
+				return nil, ErrInvalidToken
+			}
+			token.signature = sig
+		}
+	}
+
+	if token.keyID == "" || token.expires.IsZero() || token.signature == nil {
+		return nil, ErrInvalidToken
+	}
+
+	return token, nil
+}
+
+func signature(secret []byte, method, path, nonce string, expires time.Time) []byte {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%s", method, path, expires.Unix(), nonce)
+
+	return mac.Sum(nil)
+}
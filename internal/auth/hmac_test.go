@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHMACAuthenticate(t *testing.T) {
+	keys := map[string]string{"client": "s3cret"}
+	a := NewHMACAuthenticator(keys, 15*time.Minute, nil)
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		header string
+		err    error
+	}{
+		{
+			name:   "valid",
+			method: "PUT",
+			target: "/files/a?x=1",
+			header: Sign("client", "s3cret", "PUT", "/files/a?x=1", "", now.Add(time.Minute)),
+		},
+		{
+			name:   "missing header",
+			method: "PUT",
+			target: "/files/a",
+			err:    ErrMissingToken,
+		},
+		{
+			name:   "wrong scheme",
+			method: "PUT",
+			target: "/files/a",
+			header: "Bearer abc",
+			err:    ErrInvalidToken,
+		},
+		{
+			name:   "wrong secret",
+			method: "PUT",
+			target: "/files/a",
+			header: Sign("client", "other", "PUT", "/files/a", "", now.Add(time.Minute)),
+			err:    ErrInvalidToken,
+		},
+		{
+			name:   "different path",
+			method: "PUT",
+			target: "/files/b",
+			header: Sign("client", "s3cret", "PUT", "/files/a", "", now.Add(time.Minute)),
+			err:    ErrInvalidToken,
+		},
+		{
+			name:   "different method",
+			method: "DELETE",
+			target: "/files/a",
+			header: Sign("client", "s3cret", "PUT", "/files/a", "", now.Add(time.Minute)),
+			err:    ErrInvalidToken,
+		},
+		{
+			name:   "different query",
+			method: "PUT",
+			target: "/files/a?x=2",
+			header: Sign("client", "s3cret", "PUT", "/files/a?x=1", "", now.Add(time.Minute)),
+			err:    ErrInvalidToken,
+		},
+		{
+			name:   "expired",
+			method: "PUT",
+			target: "/files/a",
+			header: Sign("client", "s3cret", "PUT", "/files/a", "", now.Add(-time.Second)),
+			err:    ErrExpiredToken,
+		},
+		{
+			name:   "expiry beyond max ttl",
+			method: "PUT",
+			target: "/files/a",
+			header: Sign("client", "s3cret", "PUT", "/files/a", "", now.Add(time.Hour)),
+			err:    ErrInvalidToken,
+		},
+		{
+			name:   "unknown key",
+			method: "PUT",
+			target: "/files/a",
+			header: Sign("nobody", "s3cret", "PUT", "/files/a", "", now.Add(time.Minute)),
+			err:    ErrUnknownKey,
+		},
+		{
+			name:   "malformed signature",
+			method: "PUT",
+			target: "/files/a",
+			header: "HMAC keyId=client,expires=" + "9999999999" + ",signature=zz",
+			err:    ErrInvalidToken,
+		},
+		{
+			name:   "tampered nonce",
+			method: "PUT",
+			target: "/files/a",
+			header: strings.Replace(Sign("client", "s3cret", "PUT", "/files/a", "n1", now.Add(time.Minute)), "nonce=n1", "nonce=n2", 1),
+			err:    ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+
+			principal, err := a.Authenticate(r)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+			if tt.err == nil && principal.KeyID != "client" {
+				t.Fatalf("expected principal client, got %q", principal.KeyID)
+			}
+		})
+	}
+}
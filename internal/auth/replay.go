@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+type NonceStore interface {
+	Seen(nonce string, expires time.Time) bool
+}
+
+type ReplayCache struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+}
+
+func NewReplayCache() *ReplayCache {
+	return &ReplayCache{nonces: make(map[string]time.Time)}
+}
+
+func (c *ReplayCache) Seen(nonce string, expires time.Time) bool {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.lastSweep) > time.Minute {
+		for n, exp := range c.nonces {
+			if now.After(exp) {
+				delete(c.nonces, n)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	if exp, ok := c.nonces[nonce]; ok && !now.After(exp) {
+		return true
+	}
+
+	c.nonces[nonce] = expires
+
+	return false
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHMACReplayProtection(t *testing.T) {
+	a := NewHMACAuthenticator(map[string]string{"client": "s3cret", "other": "s3cret"}, 15*time.Minute, NewReplayCache())
+	expires := time.Now().Add(time.Minute)
+
+	request := func(keyID, nonce string) error {
+		r := httptest.NewRequest("PUT", "/files/a", nil)
+		r.Header.Set("Authorization", Sign(keyID, "s3cret", "PUT", "/files/a", nonce, expires))
+		_, err := a.Authenticate(r)
+
+		return err
+	}
+
+	if err := request("client", "n1"); err != nil {
+		t.Fatalf("first use rejected: %v", err)
+	}
+	if err := request("client", "n1"); !errors.Is(err, ErrReplayedToken) {
+		t.Fatalf("expected ErrReplayedToken on reuse, got %v", err)
+	}
+	if err := request("client", "n2"); err != nil {
+		t.Fatalf("fresh nonce rejected: %v", err)
+	}
+	if err := request("other", "n1"); err != nil {
+		t.Fatalf("same nonce under a different key rejected: %v", err)
+	}
+	if err := request("client", ""); !errors.Is(err, ErrMissingNonce) {
+		t.Fatalf("expected ErrMissingNonce, got %v", err)
+	}
+}
+
+func TestReplayRejectsBadSignatureWithoutConsumingNonce(t *testing.T) {
+	a := NewHMACAuthenticator(map[string]string{"client": "s3cret"}, 15*time.Minute, NewReplayCache())
+	expires := time.Now().Add(time.Minute)
+
+	forged := httptest.NewRequest("PUT", "/files/a", nil)
+	forged.Header.Set("Authorization", Sign("client", "wrong", "PUT", "/files/a", "n1", expires))
+	if _, err := a.Authenticate(forged); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+
+	genuine := httptest.NewRequest("PUT", "/files/a", nil)
+	genuine.Header.Set("Authorization", Sign("client", "s3cret", "PUT", "/files/a", "n1", expires))
+	if _, err := a.Authenticate(genuine); err != nil {
+		t.Fatalf("genuine request rejected after forged attempt: %v", err)
+	}
+}
+
+func TestReplayCacheExpiry(t *testing.T) {
+	c := NewReplayCache()
+
+	if c.Seen("a", time.Now().Add(time.Minute)) {
+		t.Fatal("new nonce reported as seen")
+	}
+	if !c.Seen("a", time.Now().Add(time.Minute)) {
+		t.Fatal("reused nonce not reported as seen")
+	}
+
+	if c.Seen("b", time.Now().Add(-time.Second)) {
+		t.Fatal("new nonce reported as seen")
+	}
+	if c.Seen("b", time.Now().Add(time.Minute)) {
+		t.Fatal("expired nonce still reported as seen")
+	}
+}
@@ -0,0 +1,154 @@
+package checksum
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+)
+
+const HeaderSHA256 = "Content-SHA256"
+
+const (
+	SHA256 = "sha256"
+	MD5    = "md5"
+	CRC32C = "crc32c"
+)
+
+var (
+	ErrMismatch         = errors.New("checksum mismatch")
+	ErrInvalidChecksum  = errors.New("invalid checksum")
+	ErrUnknownAlgorithm = errors.New("unknown checksum algorithm")
+)
+
+type Sums struct {
+	SHA256 string `json:"sha256"`
+	MD5    string `json:"md5,omitempty"`
+	CRC32C string `json:"crc32c,omitempty"`
+}
+
+type Reader struct {
+	r        io.Reader
+	hashes   map[string]hash.Hash
+	n        int64
+	expected []byte
+	size     int64
+	checked  bool
+	err      error
+}
+
+func IsValidAlgorithm(name string) bool {
+	switch name {
+	case SHA256, MD5, CRC32C:
+		return true
+	default:
+		return false
+	}
+}
+
+func NewReader(r io.Reader, algorithms ...string) (*Reader, error) {
+	hashes := map[string]hash.Hash{SHA256: sha256.New()}
+
+	for _, name := range algorithms {
+		switch name {
+		case SHA256:
+		case MD5:
+			hashes[MD5] = md5.New()
+		case CRC32C:
+			hashes[CRC32C] = crc32.New(crc32.MakeTable(crc32.Castagnoli))
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, name)
+		}
+	}
+
+	return &Reader{r: r, hashes: hashes}, nil
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	n, err := r.r.Read(p)
+	if n > 0 {
+		for _, h := range r.hashes {
+			h.Write(p[:n])
+		}
+		r.n += int64(n)
+	}
+
+	if r.expected != nil && !r.checked && (errors.Is(err, io.EOF) || (r.size >= 0 && r.n >= r.size)) {
+		r.checked = true
+		if r.err = r.Verify(r.expected); r.err != nil {
+			return n, r.err
+		}
+	}
+
+	return n, err
+}
+
+func (r *Reader) Enforce(expected []byte, size int64) {
+	r.expected = expected
+	r.size = size
+}
+
+func (r *Reader) BytesRead() int64 {
+	return r.n
+}
+
+func (r *Reader) Sums() Sums {
+	var sums Sums
+
+	for name, h := range r.hashes {
+		sum := hex.EncodeToString(h.Sum(nil))
+		switch name {
+		case SHA256:
+			sums.SHA256 = sum
+		case MD5:
+			sums.MD5 = sum
+		case CRC32C:
+			sums.CRC32C = sum
+		}
+	}
+
+	return sums
+}
+
+func (r *Reader) Verify(expected []byte) error {
+	if expected == nil {
+		return nil
+	}
+
+	actual := r.hashes[SHA256].Sum(nil)
+	if subtle.ConstantTimeCompare(actual, expected) != 1 {
+		return fmt.Errorf("%w: expected sha256 %s, got %s", ErrMismatch, hex.EncodeToString(expected), hex.EncodeToString(actual))
+	}
+
+	return nil
+}
+
+func ParseSHA256(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	if len(value) == hex.EncodedLen(sha256.Size) {
+		if sum, err := hex.DecodeString(value); err == nil {
+			return sum, nil
+		}
+	}
+
+	if sum, err := base64.StdEncoding.DecodeString(value); err == nil && len(sum) == sha256.Size {
+		return sum, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s must be a hex or base64 sha256 digest", ErrInvalidChecksum, HeaderSHA256)
+}
@@ -1,31 +1,56 @@
 package config
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
-	"log"
 	"log/slog"
+	"math/big"
+	"net"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/ifaisalabid1/file-upload-service/internal/checksum"
 )
 
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	AWS      AWSConfig
+	Storage  StorageConfig
 	App      AppConfig
+	Auth     AuthConfig
+	Scan     ScanConfig
+
+	sources map[string]string
 }
 
 type ServerConfig struct {
 	Port         string
 	Environment  string
 	LogLevel     slog.Level
+	LogStages    bool
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	Mode         string
+	ModeMessage  string
+	ReloadEvery  time.Duration
+	SLO          SLOConfig
+	ErrorPage    ErrorPageConfig
+}
+
+type ErrorPageConfig struct {
+	Brand    string
+	Template string
+}
+
+type SLOConfig struct {
+	AvailabilityTarget float64
+	LatencyTarget      float64
+	LatencyThreshold   time.Duration
 }
 
 type DatabaseConfig struct {
@@ -42,66 +67,219 @@ type DatabaseConfig struct {
 }
 
 type AWSConfig struct {
-	Region    string
-	AccessKey string
-	SecretKey string
-	S3Bucket  string
-	S3BaseURL string
+	Region          string
+	AccessKey       string
+	SecretKey       string
+	S3Bucket        string
+	S3BaseURL       string
+	S3UseAccelerate bool
+	S3Endpoint      string
+	S3PathStyle     bool
+	S3DisableSSL    bool
+	S3SSEMode       string
+	S3KMSKeyID      string
+}
+
+type StorageConfig struct {
+	Backend       string
+	LocalPath     string
+	MaxPresignTTL time.Duration
+	Multipart     MultipartConfig
+	EncryptionKey string
+	Compression   string
+	CompressTypes []string
+}
+
+type MultipartConfig struct {
+	Threshold      int64
+	PartSize       int64
+	Concurrency    int
+	AbortAfter     time.Duration
+	AbortInterval  time.Duration
+	Adaptive       bool
+	TargetPartTime time.Duration
 }
 
 type AppConfig struct {
-	MaxFileSize      int64
-	AllowedFileTypes []string
+	MaxFileSize        int64
+	MaxFileSizeByType  map[string]int64
+	MaxJSONBodySize    int64
+	AllowedFileTypes   []string
+	RepairJPEG         bool
+	StripImageMetadata bool
+	ThumbnailSizes     []int
+	Checksums          []string
+}
+
+const (
+	ServiceModeNormal      = "normal"
+	ServiceModeReadOnly    = "read_only"
+	ServiceModeMaintenance = "maintenance"
+)
+
+const (
+	StorageBackendS3    = "s3"
+	StorageBackendLocal = "local"
+)
+
+const (
+	SSEModeNone = "none"
+	SSEModeS3   = "sse-s3"
+	SSEModeKMS  = "sse-kms"
+)
+
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+const (
+	AuthModeNone = "none"
+	AuthModeHMAC = "hmac"
+)
+
+type AuthConfig struct {
+	Mode                 string
+	HMACKeys             map[string]string
+	HMACMaxTTL           time.Duration
+	HMACReplayProtection bool
+}
+
+type ScanConfig struct {
+	ClamAVAddr string
+	Timeout    time.Duration
+}
+
+var fileSizeOverrides = map[string]string{
+	"MAX_FILE_SIZE_IMAGE": "image/*",
+	"MAX_FILE_SIZE_VIDEO": "video/*",
+	"MAX_FILE_SIZE_AUDIO": "audio/*",
+	"MAX_FILE_SIZE_TEXT":  "text/*",
+	"MAX_FILE_SIZE_PDF":   "application/pdf",
+	"MAX_FILE_SIZE_JSON":  "application/json",
+	"MAX_FILE_SIZE_ZIP":   "application/zip",
 }
 
 func Load() (*Config, error) {
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
+	return load(func(c *Config) error {
+		return c.resolveSecrets()
+	})
+}
+
+func load(secretsFn func(*Config) error) (*Config, error) {
+	l := newLoader()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		file, err := readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		l.file = file
 	}
 
+	l.strict = isStrict(l.get("CONFIG_STRICT", ""), l.get("ENVIRONMENT", "development"))
+
 	cfg := &Config{
 		Server: ServerConfig{
-			Port:         getEnv("SERVER_PORT", "8080"),
-			Environment:  getEnv("ENVIRONMENT", "development"),
-			LogLevel:     parseLogLevel(getEnv("LOG_LEVEL", "info")),
-			ReadTimeout:  parseDuration(getEnv("READ_TIMEOUT", "15s"), 15*time.Second),
-			WriteTimeout: parseDuration(getEnv("WRITE_TIMEOUT", "15s"), 15*time.Second),
-			IdleTimeout:  parseDuration(getEnv("IDLE_TIMEOUT", "60s"), 60*time.Second),
+			Port:         l.get("SERVER_PORT", "8080"),
+			Environment:  l.get("ENVIRONMENT", "development"),
+			LogLevel:     l.logLevel("LOG_LEVEL", "info"),
+			LogStages:    l.bool("LOG_STAGE_TIMINGS", "false"),
+			ReadTimeout:  l.duration("READ_TIMEOUT", "15s"),
+			WriteTimeout: l.duration("WRITE_TIMEOUT", "15s"),
+			IdleTimeout:  l.duration("IDLE_TIMEOUT", "60s"),
+			Mode:         strings.ToLower(l.get("SERVICE_MODE", ServiceModeNormal)),
+			ModeMessage:  l.get("MAINTENANCE_MESSAGE", "The service is undergoing maintenance, please try again shortly"),
+			ReloadEvery:  l.duration("CONFIG_RELOAD_INTERVAL", "30s"),
+			SLO: SLOConfig{
+				AvailabilityTarget: l.float("SLO_AVAILABILITY_TARGET", "0.999"),
+				LatencyTarget:      l.float("SLO_LATENCY_TARGET", "0.99"),
+				LatencyThreshold:   l.duration("SLO_LATENCY_THRESHOLD", "500ms"),
+			},
+			ErrorPage: ErrorPageConfig{
+				Brand:    l.get("ERROR_PAGE_BRAND", "File Upload Service"),
+				Template: l.get("ERROR_PAGE_TEMPLATE", ""),
+			},
 		},
 
 		Database: DatabaseConfig{
-			Host:              getEnv("DB_HOST", "localhost"),
-			Port:              parseInt(getEnv("DB_PORT", "5432")),
-			User:              getEnv("DB_USER", "postgres"),
-			Password:          getEnv("DB_PASSWORD", "postgres"),
-			DBName:            getEnv("DB_NAME", "fileupload"),
-			MaxConns:          int32(parseInt(getEnv("DB_MAX_CONNS", "10"))),
-			MinConns:          int32(parseInt(getEnv("DB_MIN_CONNS", "2"))),
-			MaxConnLifetime:   parseDuration(getEnv("DB_MAX_CONN_LIFETIME", "1h"), time.Hour),
-			MaxConnIdleTime:   parseDuration(getEnv("DB_MAX_CONN_IDLE_TIME", "30m"), 30*time.Minute),
-			HealthCheckPeriod: parseDuration(getEnv("DB_HEALTH_CHECK_PERIOD", "1m"), time.Minute),
+			Host:              l.get("DB_HOST", "localhost"),
+			Port:              l.int("DB_PORT", "5432"),
+			User:              l.get("DB_USER", "postgres"),
+			Password:          l.get("DB_PASSWORD", "postgres"),
+			DBName:            l.get("DB_NAME", "fileupload"),
+			MaxConns:          l.int32("DB_MAX_CONNS", "10"),
+			MinConns:          l.int32("DB_MIN_CONNS", "2"),
+			MaxConnLifetime:   l.duration("DB_MAX_CONN_LIFETIME", "1h"),
+			MaxConnIdleTime:   l.duration("DB_MAX_CONN_IDLE_TIME", "30m"),
+			HealthCheckPeriod: l.duration("DB_HEALTH_CHECK_PERIOD", "1m"),
 		},
 
 		AWS: AWSConfig{
-			Region:    getEnv("AWS_REGION", "us-east-1"),
-			AccessKey: getEnv("AWS_ACCESS_KEY", ""),
-			SecretKey: getEnv("AWS_SECRET_KEY", ""),
-			S3Bucket:  getEnv("S3_BUCKET", ""),
-			S3BaseURL: getEnv("S3_BASE_URL", ""),
+			Region:          l.get("AWS_REGION", "us-east-1"),
+			AccessKey:       l.get("AWS_ACCESS_KEY", ""),
+			SecretKey:       l.get("AWS_SECRET_KEY", ""),
+			S3Bucket:        l.get("S3_BUCKET", ""),
+			S3BaseURL:       l.get("S3_BASE_URL", ""),
+			S3UseAccelerate: l.bool("S3_USE_ACCELERATE", "false"),
+			S3Endpoint:      l.get("S3_ENDPOINT", ""),
+			S3PathStyle:     l.bool("S3_FORCE_PATH_STYLE", "false"),
+			S3DisableSSL:    l.bool("S3_DISABLE_SSL", "false"),
+			S3SSEMode:       strings.ToLower(l.get("S3_SSE_MODE", SSEModeNone)),
+			S3KMSKeyID:      l.get("S3_KMS_KEY_ID", ""),
 		},
 
-		App: AppConfig{
-			MaxFileSize: parseInt64(getEnv("MAX_FILE_SIZE", "10_485_760")),
-			AllowedFileTypes: []string{
-				"image/jpeg",
-				"image/jpg",
-				"image/png",
-				"image/webp",
-				"image/gif",
-				"application/pdf",
-				"text/plain",
+		Storage: StorageConfig{
+			Backend:       strings.ToLower(l.get("STORAGE_BACKEND", StorageBackendS3)),
+			LocalPath:     l.get("LOCAL_STORAGE_PATH", "./data/uploads"),
+			MaxPresignTTL: l.duration("MAX_PRESIGN_TTL", "1h"),
+			Multipart: MultipartConfig{
+				Threshold:      l.size("MULTIPART_THRESHOLD", "100MiB"),
+				PartSize:       l.size("MULTIPART_PART_SIZE", "8MiB"),
+				Concurrency:    l.int("MULTIPART_CONCURRENCY", "4"),
+				AbortAfter:     l.duration("MULTIPART_ABORT_AFTER", "24h"),
+				AbortInterval:  l.duration("MULTIPART_ABORT_INTERVAL", "1h"),
+				Adaptive:       l.bool("MULTIPART_ADAPTIVE", "false"),
+				TargetPartTime: l.duration("MULTIPART_TARGET_PART_TIME", "5s"),
 			},
+			EncryptionKey: l.get("ENCRYPTION_MASTER_KEY", ""),
+			Compression:   strings.ToLower(l.get("STORAGE_COMPRESSION", CompressionNone)),
+			CompressTypes: parseList(l.get("STORAGE_COMPRESS_TYPES", "text/*,application/json,application/xml,application/x-ndjson")),
+		},
+
+		App: AppConfig{
+			MaxFileSize:        l.size("MAX_FILE_SIZE", "10MiB"),
+			MaxFileSizeByType:  l.fileSizeOverrides(),
+			MaxJSONBodySize:    l.size("MAX_JSON_BODY_SIZE", "1MiB"),
+			AllowedFileTypes:   parseList(l.get("ALLOWED_FILE_TYPES", "image/jpeg,image/jpg,image/png,image/webp,image/gif,application/pdf,text/plain")),
+			RepairJPEG:         l.bool("IMAGE_REPAIR_JPEG", "false"),
+			StripImageMetadata: l.bool("STRIP_IMAGE_METADATA", "false"),
+			ThumbnailSizes:     l.ints("THUMBNAIL_SIZES", "128,512"),
+			Checksums:          parseList(l.get("CHECKSUM_ALGORITHMS", "sha256")),
 		},
+
+		Auth: AuthConfig{
+			Mode:                 strings.ToLower(l.get("AUTH_MODE", AuthModeNone)),
+			HMACKeys:             l.keyValues("AUTH_HMAC_KEYS", ""),
+			HMACMaxTTL:           l.duration("AUTH_HMAC_MAX_TTL", "15m"),
+			HMACReplayProtection: l.bool("AUTH_HMAC_REPLAY_PROTECTION", "true"),
+		},
+
+		Scan: ScanConfig{
+			ClamAVAddr: l.get("CLAMAV_ADDR", ""),
+			Timeout:    l.duration("CLAMAV_TIMEOUT", "30s"),
+		},
+	}
+
+	cfg.sources = l.sources
+
+	if err := l.err(); err != nil {
+		return nil, fmt.Errorf("config parsing failed: %w", err)
+	}
+
+	if err := secretsFn(cfg); err != nil {
+		return nil, fmt.Errorf("config secret resolution failed: %w", err)
 	}
 
 	if err := cfg.validate(); err != nil {
@@ -112,84 +290,448 @@ func Load() (*Config, error) {
 }
 
 func (c *Config) validate() error {
-	var missing []string
+	var problems []string
+
+	if !isValidPort(c.Server.Port) {
+		problems = append(problems, "SERVER_PORT must be a port between 1 and 65535")
+	}
+	if c.Server.ReadTimeout <= 0 || c.Server.WriteTimeout <= 0 || c.Server.IdleTimeout <= 0 {
+		problems = append(problems, "READ_TIMEOUT, WRITE_TIMEOUT and IDLE_TIMEOUT must be greater than 0")
+	}
+	if c.Server.ReloadEvery < 0 {
+		problems = append(problems, "CONFIG_RELOAD_INTERVAL must not be negative")
+	}
+	if c.Server.ErrorPage.Template != "" {
+		if _, err := os.Stat(c.Server.ErrorPage.Template); err != nil {
+			problems = append(problems, "ERROR_PAGE_TEMPLATE must point to a readable template file")
+		}
+	}
+	if !inUnitInterval(c.Server.SLO.AvailabilityTarget) || !inUnitInterval(c.Server.SLO.LatencyTarget) {
+		problems = append(problems, "SLO_AVAILABILITY_TARGET and SLO_LATENCY_TARGET must be between 0 and 1")
+	}
 
 	if c.Database.Host == "" {
-		missing = append(missing, "DB_HOST is required")
+		problems = append(problems, "DB_HOST is required")
 	}
 	if c.Database.User == "" {
-		missing = append(missing, "DB_USER is required")
+		problems = append(problems, "DB_USER is required")
 	}
 	if c.Database.Password == "" {
-		missing = append(missing, "DB_Password is required")
+		problems = append(problems, "DB_PASSWORD is required")
 	}
 	if c.Database.DBName == "" {
-		missing = append(missing, "DB_NAME is required")
+		problems = append(problems, "DB_NAME is required")
+	}
+	if c.Database.Port < 1 || c.Database.Port > 65535 {
+		problems = append(problems, "DB_PORT must be between 1 and 65535")
+	}
+	if c.Database.MaxConns <= 0 {
+		problems = append(problems, "DB_MAX_CONNS must be greater than 0")
+	}
+	if c.Database.MinConns < 0 {
+		problems = append(problems, "DB_MIN_CONNS must not be negative")
+	}
+	if c.Database.MinConns > c.Database.MaxConns {
+		problems = append(problems, "DB_MIN_CONNS must not exceed DB_MAX_CONNS")
+	}
+	if c.Database.MaxConnLifetime <= 0 || c.Database.MaxConnIdleTime <= 0 || c.Database.HealthCheckPeriod <= 0 {
+		problems = append(problems, "DB_MAX_CONN_LIFETIME, DB_MAX_CONN_IDLE_TIME and DB_HEALTH_CHECK_PERIOD must be greater than 0")
+	}
+	switch c.Storage.Backend {
+	case StorageBackendS3:
+		if c.AWS.Region == "" {
+			problems = append(problems, "AWS_REGION is required")
+		}
+		if c.AWS.S3Bucket == "" {
+			problems = append(problems, "S3_BUCKET is required")
+		}
+		if c.AWS.S3UseAccelerate && c.AWS.S3Endpoint != "" {
+			problems = append(problems, "S3_USE_ACCELERATE cannot be combined with S3_ENDPOINT")
+		}
+		if c.AWS.S3UseAccelerate && c.AWS.S3PathStyle {
+			problems = append(problems, "S3_USE_ACCELERATE requires virtual-hosted-style addressing and cannot be combined with S3_FORCE_PATH_STYLE")
+		}
+		switch c.AWS.S3SSEMode {
+		case SSEModeNone, SSEModeS3, SSEModeKMS:
+		default:
+			problems = append(problems, "S3_SSE_MODE must be one of none, sse-s3, sse-kms")
+		}
+		if c.AWS.S3KMSKeyID != "" && c.AWS.S3SSEMode != SSEModeKMS {
+			problems = append(problems, "S3_KMS_KEY_ID requires S3_SSE_MODE=sse-kms")
+		}
+		if (c.AWS.AccessKey == "") != (c.AWS.SecretKey == "") {
+			problems = append(problems, "AWS_ACCESS_KEY and AWS_SECRET_KEY must be set together")
+		}
+	case StorageBackendLocal:
+		if c.Storage.LocalPath == "" {
+			problems = append(problems, "LOCAL_STORAGE_PATH is required")
+		}
+	default:
+		problems = append(problems, "STORAGE_BACKEND must be one of s3, local")
+	}
+
+	if c.Storage.MaxPresignTTL <= 0 || c.Storage.MaxPresignTTL > 7*24*time.Hour {
+		problems = append(problems, "MAX_PRESIGN_TTL must be between 1s and 168h")
+	}
+	if c.Storage.EncryptionKey != "" {
+		if key, err := base64.StdEncoding.DecodeString(c.Storage.EncryptionKey); err != nil || len(key) != 32 {
+			problems = append(problems, "ENCRYPTION_MASTER_KEY must be a base64-encoded 32-byte key")
+		}
 	}
-	if c.AWS.Region == "" {
-		missing = append(missing, "AWS_REGION is required")
+	switch c.Storage.Compression {
+	case CompressionNone, CompressionGzip, CompressionZstd:
+	default:
+		problems = append(problems, "STORAGE_COMPRESSION must be one of none, gzip, zstd")
+	}
+	for _, t := range c.Storage.CompressTypes {
+		if !isValidTypePattern(t) {
+			problems = append(problems, fmt.Sprintf("STORAGE_COMPRESS_TYPES entry %q is not a valid MIME type", t))
+		}
+	}
+	if c.Storage.Multipart.PartSize <= 0 {
+		problems = append(problems, "MULTIPART_PART_SIZE must be greater than 0")
+	}
+	if c.Storage.Multipart.Threshold <= 0 || c.Storage.Multipart.Threshold > 5<<30 {
+		problems = append(problems, "MULTIPART_THRESHOLD must be between 1B and 5GiB, the S3 single PUT limit")
+	}
+	if c.Storage.Multipart.Concurrency < 1 {
+		problems = append(problems, "MULTIPART_CONCURRENCY must be at least 1")
+	}
+	if c.Storage.Multipart.AbortAfter <= 0 {
+		problems = append(problems, "MULTIPART_ABORT_AFTER must be greater than 0")
+	}
+	if c.Storage.Multipart.AbortInterval < 0 {
+		problems = append(problems, "MULTIPART_ABORT_INTERVAL must not be negative")
+	}
+	if c.Storage.Multipart.Adaptive && c.Storage.Multipart.TargetPartTime <= 0 {
+		problems = append(problems, "MULTIPART_TARGET_PART_TIME must be greater than 0")
+	}
+	if c.App.MaxFileSize <= 0 {
+		problems = append(problems, "MAX_FILE_SIZE must be greater than 0")
+	}
+	if c.App.MaxJSONBodySize <= 0 {
+		problems = append(problems, "MAX_JSON_BODY_SIZE must be greater than 0")
 	}
-	if c.AWS.S3Bucket == "" {
-		missing = append(missing, "S3_BUCKET is required")
+	for t, size := range c.App.MaxFileSizeByType {
+		if size <= 0 {
+			problems = append(problems, fmt.Sprintf("max file size for %s must be greater than 0", t))
+		}
+	}
+	if len(c.App.AllowedFileTypes) == 0 {
+		problems = append(problems, "ALLOWED_FILE_TYPES must list at least one type")
+	}
+	for _, t := range c.App.AllowedFileTypes {
+		if !isValidTypePattern(t) {
+			problems = append(problems, fmt.Sprintf("ALLOWED_FILE_TYPES entry %q is not a valid MIME type", t))
+		}
+	}
+	for _, size := range c.App.ThumbnailSizes {
+		if size < 16 || size > 4096 {
+			problems = append(problems, fmt.Sprintf("THUMBNAIL_SIZES entry %d must be between 16 and 4096", size))
+		}
+	}
+	for _, name := range c.App.Checksums {
+		if !checksum.IsValidAlgorithm(name) {
+			problems = append(problems, fmt.Sprintf("CHECKSUM_ALGORITHMS entry %q must be one of sha256, md5, crc32c", name))
+		}
+	}
+	if !IsValidServiceMode(c.Server.Mode) {
+		problems = append(problems, "SERVICE_MODE must be one of normal, read_only, maintenance")
+	}
+	if c.Auth.Mode != AuthModeNone && c.Auth.Mode != AuthModeHMAC {
+		problems = append(problems, "AUTH_MODE must be one of none, hmac")
+	}
+	if c.Auth.Mode == AuthModeHMAC && len(c.Auth.HMACKeys) == 0 {
+		problems = append(problems, "AUTH_HMAC_KEYS is required when AUTH_MODE is hmac")
+	}
+	if c.Auth.Mode == AuthModeHMAC && c.Auth.HMACMaxTTL <= 0 {
+		problems = append(problems, "AUTH_HMAC_MAX_TTL must be greater than 0")
+	}
+	if c.Scan.ClamAVAddr != "" {
+		if _, _, err := net.SplitHostPort(c.Scan.ClamAVAddr); err != nil {
+			problems = append(problems, "CLAMAV_ADDR must be a host:port address")
+		}
+		if c.Scan.Timeout <= 0 {
+			problems = append(problems, "CLAMAV_TIMEOUT must be greater than 0")
+		}
 	}
 
-	if len(missing) > 0 {
-		return fmt.Errorf("missing required environment variables %s", strings.Join(missing, ", "))
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, ", "))
 	}
 
 	return nil
 }
 
+func IsValidServiceMode(mode string) bool {
+	switch mode {
+	case ServiceModeNormal, ServiceModeReadOnly, ServiceModeMaintenance:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a AppConfig) IsAllowedType(mediaType string) bool {
+	mediaType = strings.ToLower(mediaType)
+	major, _, _ := strings.Cut(mediaType, "/")
+
+	for _, allowed := range a.AllowedFileTypes {
+		if allowed == mediaType || allowed == major+"/*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a AppConfig) MaxFileSizeFor(mediaType string) int64 {
+	mediaType = strings.ToLower(mediaType)
+	major, _, _ := strings.Cut(mediaType, "/")
+
+	if size, ok := a.MaxFileSizeByType[mediaType]; ok {
+		return size
+	}
+	if size, ok := a.MaxFileSizeByType[major+"/*"]; ok {
+		return size
+	}
+
+	return a.MaxFileSize
+}
+
+func (s StorageConfig) IsCompressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	major, _, _ := strings.Cut(mediaType, "/")
+
+	for _, t := range s.CompressTypes {
+		if t == mediaType || t == major+"/*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isValidPort(port string) bool {
+	p, err := strconv.Atoi(port)
+
+	return err == nil && p >= 1 && p <= 65535
+}
+
+func inUnitInterval(f float64) bool {
+	return f > 0 && f < 1
+}
+
+func isValidTypePattern(pattern string) bool {
+	major, minor, ok := strings.Cut(pattern, "/")
+	if !ok || major == "" || minor == "" || major == "*" {
+		return false
+	}
+
+	return !strings.ContainsAny(major+minor, " ;,") && (minor == "*" || !strings.Contains(minor, "*"))
+}
+
+func (a AWSConfig) S3EndpointURL() string {
+	if a.S3Endpoint == "" {
+		return ""
+	}
+
+	host := a.S3Endpoint
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+
+	if a.S3DisableSSL {
+		return "http://" + host
+	}
+	if strings.HasPrefix(a.S3Endpoint, "http://") {
+		return a.S3Endpoint
+	}
+
+	return "https://" + host
+}
+
 func (c *Config) GetDSN() string {
 	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable", c.Database.User, c.Database.Password, c.Database.Host, c.Database.Port, c.Database.DBName)
 }
 
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+func isStrict(value, environment string) bool {
+	if strict, err := strconv.ParseBool(value); err == nil {
+		return strict
 	}
 
-	return defaultValue
+	return environment == "production"
 }
 
-func parseInt(value string) int {
-	i, err := strconv.Atoi(value)
+func parseInt(value string) (int, error) {
+	i, err := strconv.Atoi(strings.TrimSpace(value))
 	if err != nil {
-		return 0
+		return 0, fmt.Errorf("invalid integer %q", value)
 	}
 
-	return i
+	return i, nil
 }
 
-func parseInt64(value string) int64 {
-	i, err := strconv.ParseInt(value, 10, 64)
+func parseInt32(value string) (int32, error) {
+	i, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
 	if err != nil {
-		return 0
+		return 0, fmt.Errorf("invalid integer %q", value)
 	}
 
-	return i
+	return int32(i), nil
 }
 
-func parseDuration(value string, defaultValue time.Duration) time.Duration {
-	d, err := time.ParseDuration(value)
+func parseIntList(value string) ([]int, error) {
+	var items []int
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+
+		i, err := parseInt(item)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+
+	return items, nil
+}
+
+func parseList(value string) []string {
+	var items []string
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func parseKeyValues(value string) (map[string]string, error) {
+	pairs := make(map[string]string)
+
+	var errs []error
+	for i, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		key, val, ok := strings.Cut(item, ":")
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("entry %d is not in id:secret form", i+1))
+		case key == "":
+			errs = append(errs, fmt.Errorf("entry %d has an empty id", i+1))
+		case val == "":
+			errs = append(errs, fmt.Errorf("entry %d (%q) has an empty secret", i+1, key))
+		case pairs[key] != "":
+			errs = append(errs, fmt.Errorf("entry %d repeats id %q", i+1, key))
+		default:
+			pairs[key] = val
+		}
+	}
+
+	return pairs, errors.Join(errs...)
+}
+
+func parseBool(value string) (bool, error) {
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
 	if err != nil {
-		return defaultValue
+		return false, fmt.Errorf("invalid boolean %q", value)
 	}
-	return d
+
+	return b, nil
 }
 
-func parseLogLevel(level string) slog.Level {
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1e3,
+	"kb":  1e3,
+	"m":   1e6,
+	"mb":  1e6,
+	"g":   1e9,
+	"gb":  1e9,
+	"t":   1e12,
+	"tb":  1e12,
+	"ki":  1 << 10,
+	"kib": 1 << 10,
+	"mi":  1 << 20,
+	"mib": 1 << 20,
+	"gi":  1 << 30,
+	"gib": 1 << 30,
+	"ti":  1 << 40,
+	"tib": 1 << 40,
+}
+
+func parseSize(value string) (int64, error) {
+	s := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(value), "_", ""))
+
+	split := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if split == -1 {
+		split = len(s)
+	}
+
+	number, unit := s[:split], strings.TrimSpace(s[split:])
+
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", value, unit)
+	}
+
+	size, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+
+	size.Mul(size, new(big.Rat).SetInt64(multiplier))
+	if !size.IsInt() {
+		return 0, fmt.Errorf("invalid size %q: not a whole number of bytes", value)
+	}
+	if !size.Num().IsInt64() {
+		return 0, fmt.Errorf("invalid size %q: too large", value)
+	}
+
+	return size.Num().Int64(), nil
+}
+
+func parseFloat(value string) (float64, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+
+	return f, nil
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	return d, nil
+}
 
-	switch strings.ToLower(level) {
+func parseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
 	case "debug":
-		return slog.LevelDebug
+		return slog.LevelDebug, nil
 	case "info":
-		return slog.LevelInfo
+		return slog.LevelInfo, nil
 	case "warn":
-		return slog.LevelWarn
+		return slog.LevelWarn, nil
 	case "error":
-		return slog.LevelError
+		return slog.LevelError, nil
 	default:
-		return slog.LevelInfo
+		return slog.LevelInfo, fmt.Errorf("invalid log level %q", level)
 	}
 }
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"maps"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr string
+	}{
+		{"0", 0, ""},
+		{"1024", 1024, ""},
+		{" 10MiB ", 10 << 20, ""},
+		{"10 mb", 10_000_000, ""},
+		{"1_000", 1000, ""},
+		{"1.5KiB", 1536, ""},
+		{"1.1KB", 1100, ""},
+		{"0.5gi", 1 << 29, ""},
+		{"2TiB", 2 << 40, ""},
+		{"9223372036854775807", math.MaxInt64, ""},
+		{"9223372036854775808", 0, "too large"},
+		{"8388608TiB", 0, "too large"},
+		{"99999999999999999999", 0, "too large"},
+		{"1.5", 0, "not a whole number of bytes"},
+		{"0.1KiB", 0, "not a whole number of bytes"},
+		{"10XB", 0, "unknown unit"},
+		{"-5", 0, "unknown unit"},
+		{"", 0, "invalid size"},
+		{"MiB", 0, "invalid size"},
+		{"1.2.3", 0, "invalid size"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSize(tt.value)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseSize(%q) = %d, %v; want error containing %q", tt.value, got, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseSize(%q) = %d, %v; want %d", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestLoaderSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		strict  bool
+		want    int64
+		wantErr bool
+	}{
+		{"valid strict", "2MiB", true, 2 << 20, false},
+		{"valid lenient", "2MiB", false, 2 << 20, false},
+		{"invalid strict", "1.5", true, 0, true},
+		{"invalid lenient falls back to default", "1.5", false, 10 << 20, false},
+		{"overflow strict", "9223372036854775808", true, 0, true},
+		{"overflow lenient falls back to default", "9223372036854775808", false, 10 << 20, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &loader{
+				sources: make(map[string]string),
+				file:    map[string]string{"MAX_FILE_SIZE": tt.value},
+				strict:  tt.strict,
+			}
+
+			got := l.size("MAX_FILE_SIZE", "10MiB")
+			if err := l.err(); (err != nil) != tt.wantErr {
+				t.Fatalf("loader error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Fatalf("size = %d, want %d", got, tt.want)
+			}
+			if l.sources["MAX_FILE_SIZE"] != SourceFile {
+				t.Fatalf("source = %q, want %q", l.sources["MAX_FILE_SIZE"], SourceFile)
+			}
+		})
+	}
+}
+
+func TestLoaderFileSizeOverrides(t *testing.T) {
+	file := map[string]string{
+		"MAX_FILE_SIZE_IMAGE": "5MiB",
+		"MAX_FILE_SIZE_PDF":   "1.5",
+	}
+
+	lenient := &loader{sources: make(map[string]string), file: file}
+	sizes := lenient.fileSizeOverrides()
+	if err := lenient.err(); err != nil {
+		t.Fatalf("lenient loader returned %v", err)
+	}
+	if sizes[fileSizeOverrides["MAX_FILE_SIZE_IMAGE"]] != 5<<20 {
+		t.Fatalf("image override = %d, want %d", sizes[fileSizeOverrides["MAX_FILE_SIZE_IMAGE"]], 5<<20)
+	}
+	if _, ok := sizes["application/pdf"]; ok {
+		t.Fatal("invalid override was not ignored")
+	}
+
+	strict := &loader{sources: make(map[string]string), file: file, strict: true}
+	strict.fileSizeOverrides()
+	if err := strict.err(); err == nil || !strings.Contains(err.Error(), "MAX_FILE_SIZE_PDF") {
+		t.Fatalf("strict loader returned %v, want an error for MAX_FILE_SIZE_PDF", err)
+	}
+}
+
+func TestLoaderKeyValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]string
+		wantErr string
+	}{
+		{"valid", "client-a:secret1, client-b:se:cret2", map[string]string{"client-a": "secret1", "client-b": "se:cret2"}, ""},
+		{"empty", "", map[string]string{}, ""},
+		{"trailing comma", "client-a:secret1,", map[string]string{"client-a": "secret1"}, ""},
+		{"missing separator", "client-a:secret1,client-bsecret2", map[string]string{"client-a": "secret1"}, "entry 2 is not in id:secret form"},
+		{"empty secret", "client-a:", map[string]string{}, `entry 1 ("client-a") has an empty secret`},
+		{"empty id", ":secret", map[string]string{}, "entry 1 has an empty id"},
+		{"duplicate id", "client-a:one,client-a:two", map[string]string{"client-a": "one"}, `entry 2 repeats id "client-a"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, strict := range []bool{true, false} {
+				l := &loader{
+					sources: make(map[string]string),
+					file:    map[string]string{"AUTH_HMAC_KEYS": tt.value},
+					strict:  strict,
+				}
+
+				got := l.keyValues("AUTH_HMAC_KEYS", "")
+				if !maps.Equal(got, tt.want) {
+					t.Fatalf("strict=%v: keys = %v, want %v", strict, got, tt.want)
+				}
+
+				err := l.err()
+				switch {
+				case strict && tt.wantErr != "":
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Fatalf("strict loader returned %v, want error containing %q", err, tt.wantErr)
+					}
+					if strings.Contains(err.Error(), "secret2") {
+						t.Fatalf("error leaks the secret: %v", err)
+					}
+				case err != nil:
+					t.Fatalf("strict=%v: unexpected error %v", strict, err)
+				}
+			}
+		})
+	}
+}
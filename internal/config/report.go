@@ -0,0 +1,55 @@
+package config
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+)
+
+type StartupReport struct {
+	Environment string            `json:"environment"`
+	Sources     map[string]string `json:"sources"`
+	Features    map[string]any    `json:"features"`
+}
+
+func (c *Config) StartupReport() StartupReport {
+	return StartupReport{
+		Environment: c.Server.Environment,
+		Sources:     maps.Clone(c.sources),
+		Features: map[string]any{
+			"service_mode":             c.Server.Mode,
+			"storage_backend":          c.Storage.Backend,
+			"s3_transfer_acceleration": c.AWS.S3UseAccelerate,
+			"aws_static_credentials":   c.AWS.AccessKey != "",
+			"s3_custom_endpoint":       c.AWS.S3Endpoint != "",
+			"s3_sse_mode":              c.AWS.S3SSEMode,
+			"envelope_encryption":      c.Storage.EncryptionKey != "",
+			"storage_compression":      c.Storage.Compression,
+			"multipart_adaptive":       c.Storage.Multipart.Adaptive,
+			"auth_mode":                c.Auth.Mode,
+			"hmac_replay_protection":   c.Auth.Mode == AuthModeHMAC && c.Auth.HMACReplayProtection,
+			"stage_timing_logs":        c.Server.LogStages,
+			"strip_image_metadata":     c.App.StripImageMetadata,
+			"antivirus_scanning":       c.Scan.ClamAVAddr != "",
+			"allowed_file_types":       c.App.AllowedFileTypes,
+		},
+	}
+}
+
+func (r StartupReport) Attrs() []slog.Attr {
+	sources := make([]any, 0, len(r.Sources))
+	for _, key := range slices.Sorted(maps.Keys(r.Sources)) {
+		sources = append(sources, slog.String(key, r.Sources[key]))
+	}
+
+	features := make([]any, 0, len(r.Features))
+	for _, key := range slices.Sorted(maps.Keys(r.Features)) {
+		features = append(features, slog.Any(key, r.Features[key]))
+	}
+
+	return []slog.Attr{
+		slog.String("environment", r.Environment),
+		slog.Group("sources", sources...),
+		slog.Group("features", features...),
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/secrets"
+)
+
+func (c *Config) secretRefs() map[string]*string {
+	return map[string]*string{
+		"DB_PASSWORD":           &c.Database.Password,
+		"AWS_ACCESS_KEY":        &c.AWS.AccessKey,
+		"AWS_SECRET_KEY":        &c.AWS.SecretKey,
+		"ENCRYPTION_MASTER_KEY": &c.Storage.EncryptionKey,
+	}
+}
+
+func (c *Config) resolveSecrets() error {
+	refs := c.secretRefs()
+
+	var resolver *secrets.Resolver
+	var errs []error
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for key, value := range refs {
+		if !secrets.IsReference(*value) {
+			continue
+		}
+
+		if resolver == nil {
+			resolver = secrets.NewResolver(c.AWS.Region)
+		}
+
+		secret, err := resolver.Resolve(ctx, *value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		*value = secret
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Config) inheritSecrets(from *Config) {
+	current := from.secretRefs()
+
+	for key, value := range c.secretRefs() {
+		if secrets.IsReference(*value) {
+			*value = *current[key]
+		}
+	}
+}
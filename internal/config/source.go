@@ -0,0 +1,168 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+const (
+	SourceEnv     = "env"
+	SourceDotEnv  = ".env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+type loader struct {
+	dotenv  map[string]string
+	sources map[string]string
+	file    map[string]string
+	strict  bool
+	errs    []error
+}
+
+var (
+	dotenvOnce     sync.Once
+	dotenvInjected map[string]bool
+)
+
+func newLoader() *loader {
+	return &loader{dotenv: readDotEnv(), sources: make(map[string]string)}
+}
+
+func readDotEnv() map[string]string {
+	values, err := godotenv.Read()
+
+	dotenvOnce.Do(func() {
+		if err != nil {
+			log.Println("No .env file found")
+		}
+
+		dotenvInjected = make(map[string]bool)
+		for key, value := range values {
+			if _, exists := os.LookupEnv(key); !exists {
+				os.Setenv(key, value)
+				dotenvInjected[key] = true
+			}
+		}
+	})
+
+	return values
+}
+
+func (l *loader) get(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists && !dotenvInjected[key] {
+		l.sources[key] = SourceEnv
+		return value
+	}
+
+	if value, ok := l.dotenv[key]; ok {
+		l.sources[key] = SourceDotEnv
+		return value
+	}
+
+	if value, ok := l.file[key]; ok {
+		l.sources[key] = SourceFile
+		return value
+	}
+
+	l.sources[key] = SourceDefault
+
+	return defaultValue
+}
+
+func (l *loader) fileSizeOverrides() map[string]int64 {
+	sizes := make(map[string]int64)
+
+	for key, mediaType := range fileSizeOverrides {
+		value := l.get(key, "")
+		if value == "" {
+			continue
+		}
+
+		size, err := parseSize(value)
+		if err != nil {
+			if l.strict {
+				l.errs = append(l.errs, fmt.Errorf("%s: %w", key, err))
+			} else {
+				log.Printf("%s: %v, ignoring override", key, err)
+			}
+			continue
+		}
+		sizes[mediaType] = size
+	}
+
+	return sizes
+}
+
+func (l *loader) keyValues(key, defaultValue string) map[string]string {
+	pairs, err := parseKeyValues(l.get(key, defaultValue))
+	if err != nil {
+		if l.strict {
+			l.errs = append(l.errs, fmt.Errorf("%s: %w", key, err))
+		} else {
+			log.Printf("%s: %v, ignoring malformed entries", key, err)
+		}
+	}
+
+	return pairs
+}
+
+func (l *loader) int(key, defaultValue string) int {
+	return lookup(l, key, defaultValue, parseInt)
+}
+
+func (l *loader) int32(key, defaultValue string) int32 {
+	return lookup(l, key, defaultValue, parseInt32)
+}
+
+func (l *loader) ints(key, defaultValue string) []int {
+	return lookup(l, key, defaultValue, parseIntList)
+}
+
+func (l *loader) float(key, defaultValue string) float64 {
+	return lookup(l, key, defaultValue, parseFloat)
+}
+
+func (l *loader) bool(key, defaultValue string) bool {
+	return lookup(l, key, defaultValue, parseBool)
+}
+
+func (l *loader) duration(key, defaultValue string) time.Duration {
+	return lookup(l, key, defaultValue, parseDuration)
+}
+
+func (l *loader) size(key, defaultValue string) int64 {
+	return lookup(l, key, defaultValue, parseSize)
+}
+
+func (l *loader) logLevel(key, defaultValue string) slog.Level {
+	return lookup(l, key, defaultValue, parseLogLevel)
+}
+
+func lookup[T any](l *loader, key, defaultValue string, parse func(string) (T, error)) T {
+	value, err := parse(l.get(key, defaultValue))
+	if err == nil {
+		return value
+	}
+
+	if l.strict {
+		l.errs = append(l.errs, fmt.Errorf("%s: %w", key, err))
+		return value
+	}
+
+	log.Printf("%s: %v, using default %q", key, err, defaultValue)
+	value, _ = parse(defaultValue)
+
+	return value
+}
+
+func (l *loader) err() error {
+	return errors.Join(l.errs...)
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"context"
+	"log"
+	"maps"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+type Watcher struct {
+	current  atomic.Pointer[Config]
+	interval time.Duration
+
+	mu          sync.Mutex
+	subscribers []func(*Config)
+	modTime     time.Time
+}
+
+func NewWatcher(cfg *Config) *Watcher {
+	w := &Watcher{interval: cfg.Server.ReloadEvery}
+	w.current.Store(cfg)
+	w.modTime = configFileModTime()
+
+	return w
+}
+
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+func (w *Watcher) Subscribe(fn func(*Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers = append(w.subscribers, fn)
+}
+
+func (w *Watcher) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if w.interval > 0 && os.Getenv("CONFIG_FILE") != "" {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			w.reload()
+		case <-tick:
+			if w.fileChanged() {
+				w.reload()
+			}
+		}
+	}
+}
+
+func (w *Watcher) Reload() error {
+	current := w.current.Load()
+
+	next, err := load(func(c *Config) error {
+		c.inheritSecrets(current)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	updated := *current
+	updated.Server.LogLevel = next.Server.LogLevel
+	updated.Server.LogStages = next.Server.LogStages
+	updated.App = next.App
+	updated.sources = maps.Clone(current.sources)
+	for key, source := range next.sources {
+		if isTunable(key) {
+			updated.sources[key] = source
+		}
+	}
+
+	w.current.Store(&updated)
+
+	w.mu.Lock()
+	subscribers := append([]func(*Config){}, w.subscribers...)
+	w.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(&updated)
+	}
+
+	return nil
+}
+
+func isTunable(key string) bool {
+	switch key {
+	case "LOG_LEVEL", "LOG_STAGE_TIMINGS", "MAX_FILE_SIZE", "MAX_JSON_BODY_SIZE", "ALLOWED_FILE_TYPES",
+		"IMAGE_REPAIR_JPEG", "STRIP_IMAGE_METADATA", "THUMBNAIL_SIZES", "CHECKSUM_ALGORITHMS":
+		return true
+	}
+
+	_, ok := fileSizeOverrides[key]
+
+	return ok
+}
+
+func (w *Watcher) reload() {
+	if err := w.Reload(); err != nil {
+		log.Printf("config reload failed, keeping current settings: %v", err)
+	}
+}
+
+func (w *Watcher) fileChanged() bool {
+	modTime := configFileModTime()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if modTime.Equal(w.modTime) {
+		return false
+	}
+	w.modTime = modTime
+
+	return true
+}
+
+func configFileModTime() time.Time {
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		return time.Time{}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
@@ -0,0 +1,82 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+const (
+	KeySize   = 32
+	NonceSize = 12
+
+	WrappedKeySize = NonceSize + KeySize + 16
+)
+
+var ErrInvalidKey = errors.New("invalid encryption key")
+
+type Envelope struct {
+	master cipher.AEAD
+}
+
+func NewEnvelope(masterKey []byte) (*Envelope, error) {
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{master: aead}, nil
+}
+
+func (e *Envelope) NewDataKey() (plain, wrapped []byte, err error) {
+	plain = make([]byte, KeySize)
+	if _, err := rand.Read(plain); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	nonce := make([]byte, NonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	wrapped = e.master.Seal(nonce, nonce, plain, nil)
+
+	return plain, wrapped, nil
+}
+
+func (e *Envelope) Unwrap(wrapped []byte) ([]byte, error) {
+	if len(wrapped) != WrappedKeySize {
+		return nil, ErrInvalidKey
+	}
+
+	plain, err := e.master.Open(nil, wrapped[:NonceSize], wrapped[NonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to unwrap data key", ErrInvalidKey)
+	}
+
+	return plain, nil
+}
+
+func NewNonce() ([]byte, error) {
+	nonce := make([]byte, NonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return nonce, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: must be %d bytes", ErrInvalidKey, KeySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,130 @@
+package encryption
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	ChunkSize = 64 << 10
+
+	tagSize = 16
+)
+
+var ErrCorrupt = errors.New("encrypted data is corrupt or truncated")
+
+func CiphertextSize(plaintextSize int64) int64 {
+	return plaintextSize + chunkCount(plaintextSize)*tagSize
+}
+
+func PlaintextSize(ciphertextSize int64) int64 {
+	chunks := (ciphertextSize + ChunkSize + tagSize - 1) / (ChunkSize + tagSize)
+
+	return ciphertextSize - chunks*tagSize
+}
+
+func chunkCount(plaintextSize int64) int64 {
+	return max(1, (plaintextSize+ChunkSize-1)/ChunkSize)
+}
+
+type streamReader struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	nonce   []byte
+	counter uint32
+	in      []byte
+	buf     []byte
+	out     []byte
+	decrypt bool
+	done    bool
+	err     error
+}
+
+func NewEncryptReader(r io.Reader, dataKey, nonce []byte) (io.Reader, error) {
+	return newStreamReader(r, dataKey, nonce, false, ChunkSize)
+}
+
+func NewDecryptReader(r io.Reader, dataKey, nonce []byte) (io.Reader, error) {
+	return newStreamReader(r, dataKey, nonce, true, ChunkSize+tagSize)
+}
+
+func newStreamReader(r io.Reader, dataKey, nonce []byte, decrypt bool, chunk int) (*streamReader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != NonceSize {
+		return nil, ErrInvalidKey
+	}
+
+	return &streamReader{
+		src:     bufio.NewReaderSize(r, chunk+1),
+		aead:    aead,
+		nonce:   nonce,
+		in:      make([]byte, chunk),
+		decrypt: decrypt,
+	}, nil
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	for len(s.out) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		if s.done {
+			return 0, io.EOF
+		}
+		s.next()
+	}
+
+	n := copy(p, s.out)
+	s.out = s.out[n:]
+
+	return n, nil
+}
+
+func (s *streamReader) next() {
+	n, err := io.ReadFull(s.src, s.in)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		s.err = err
+		return
+	}
+
+	final := false
+	if _, err := s.src.Peek(1); errors.Is(err, io.EOF) {
+		final = true
+	}
+
+	nonce := s.chunkNonce()
+	aad := []byte{0}
+	if final {
+		aad[0] = 1
+	}
+
+	if s.decrypt {
+		s.buf, err = s.aead.Open(s.buf[:0], nonce, s.in[:n], aad)
+		if err != nil {
+			s.err = ErrCorrupt
+			return
+		}
+	} else {
+		s.buf = s.aead.Seal(s.buf[:0], nonce, s.in[:n], aad)
+	}
+
+	s.out = s.buf
+	s.counter++
+	s.done = final
+}
+
+func (s *streamReader) chunkNonce() []byte {
+	nonce := make([]byte, NonceSize)
+	copy(nonce, s.nonce)
+
+	tail := binary.BigEndian.Uint32(nonce[NonceSize-4:]) ^ s.counter
+	binary.BigEndian.PutUint32(nonce[NonceSize-4:], tail)
+
+	return nonce
+}
@@ -0,0 +1,164 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+)
+
+func newKeyAndNonce(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+
+	nonce, err := NewNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key, nonce
+}
+
+func encrypt(t *testing.T, plaintext, key, nonce []byte) []byte {
+	t.Helper()
+
+	r, err := NewEncryptReader(bytes.NewReader(plaintext), key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ciphertext
+}
+
+func decrypt(ciphertext, key, nonce []byte) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(ciphertext), key, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	sizes := []int{0, 1, ChunkSize - 1, ChunkSize, ChunkSize + 1, 3 * ChunkSize, 3*ChunkSize + 17}
+
+	for _, size := range sizes {
+		key, nonce := newKeyAndNonce(t)
+
+		plaintext := make([]byte, size)
+		rand.Read(plaintext)
+
+		ciphertext := encrypt(t, plaintext, key, nonce)
+		if got := int64(len(ciphertext)); got != CiphertextSize(int64(size)) {
+			t.Errorf("size %d: ciphertext is %d bytes, CiphertextSize says %d", size, got, CiphertextSize(int64(size)))
+		}
+		if got := PlaintextSize(int64(len(ciphertext))); got != int64(size) {
+			t.Errorf("size %d: PlaintextSize returned %d", size, got)
+		}
+
+		decrypted, err := decrypt(ciphertext, key, nonce)
+		if err != nil {
+			t.Fatalf("size %d: decrypt failed: %v", size, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("size %d: round trip mismatch", size)
+		}
+	}
+}
+
+func TestStreamRejectsTampering(t *testing.T) {
+	key, nonce := newKeyAndNonce(t)
+
+	plaintext := make([]byte, 2*ChunkSize+10)
+	rand.Read(plaintext)
+
+	ciphertext := encrypt(t, plaintext, key, nonce)
+	sealed := ChunkSize + tagSize
+
+	swapped := bytes.Clone(ciphertext)
+	copy(swapped[:sealed], ciphertext[sealed:2*sealed])
+	copy(swapped[sealed:2*sealed], ciphertext[:sealed])
+
+	flipped := bytes.Clone(ciphertext)
+	flipped[sealed+5] ^= 0x01
+
+	otherKey, _ := newKeyAndNonce(t)
+
+	tests := []struct {
+		name       string
+		ciphertext []byte
+		key        []byte
+	}{
+		{"truncated at chunk boundary", ciphertext[:2*sealed], key},
+		{"truncated mid chunk", ciphertext[:sealed+100], key},
+		{"final chunk dropped to first", ciphertext[:sealed], key},
+		{"reordered chunks", swapped, key},
+		{"bit flip", flipped, key},
+		{"empty", nil, key},
+		{"wrong key", ciphertext, otherKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decrypt(tt.ciphertext, tt.key, nonce)
+			if !errors.Is(err, ErrCorrupt) {
+				t.Fatalf("expected ErrCorrupt, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStreamRejectsAppendedChunk(t *testing.T) {
+	key, nonce := newKeyAndNonce(t)
+
+	ciphertext := encrypt(t, make([]byte, ChunkSize), key, nonce)
+	extended := append(bytes.Clone(ciphertext), ciphertext...)
+
+	if _, err := decrypt(extended, key, nonce); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestEnvelopeWrapUnwrap(t *testing.T) {
+	master, _ := newKeyAndNonce(t)
+
+	envelope, err := NewEnvelope(master)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, wrapped, err := envelope.NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wrapped) != WrappedKeySize {
+		t.Fatalf("wrapped key is %d bytes, want %d", len(wrapped), WrappedKeySize)
+	}
+
+	unwrapped, err := envelope.Unwrap(wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unwrapped, plain) {
+		t.Fatal("unwrapped key does not match")
+	}
+
+	wrapped[len(wrapped)-1] ^= 0x01
+	if _, err := envelope.Unwrap(wrapped); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("expected ErrInvalidKey for tampered key, got %v", err)
+	}
+
+	if _, err := NewEnvelope(master[:16]); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("expected ErrInvalidKey for short master key, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package errorpage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/config"
+)
+
+const defaultTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} · {{.Brand}}</title>
+<style>
+body{margin:0;font-family:system-ui,sans-serif;background:#f5f6f8;color:#1f2328;display:flex;min-height:100vh;align-items:center;justify-content:center}
+main{max-width:28rem;padding:2rem;background:#fff;border-radius:8px;box-shadow:0 1px 3px rgba(0,0,0,.1);text-align:center}
+h1{font-size:1.25rem;margin:0 0 .5rem}
+p{margin:0;color:#57606a}
+footer{margin-top:1.5rem;font-size:.8rem;color:#8c959f}
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+<p>{{.Message}}</p>
+<footer>{{.Brand}} · {{.Status}}</footer>
+</main>
+</body>
+</html>
+`
+
+type Page struct {
+	Brand   string
+	Status  int
+	Title   string
+	Message string
+}
+
+type Renderer struct {
+	brand string
+	tmpl  *template.Template
+}
+
+func New(cfg config.ErrorPageConfig) (*Renderer, error) {
+	tmpl := template.New("errorpage")
+
+	var err error
+	if cfg.Template != "" {
+		tmpl, err = tmpl.ParseFiles(cfg.Template)
+		if err == nil {
+			tmpl = tmpl.Lookup(filepath.Base(cfg.Template))
+		}
+	} else {
+		tmpl, err = tmpl.Parse(defaultTemplate)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse error page template: %w", err)
+	}
+
+	return &Renderer{brand: cfg.Brand, tmpl: tmpl}, nil
+}
+
+func (rd *Renderer) Write(w http.ResponseWriter, r *http.Request, status int, message string) {
+	if !WantsHTML(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+		return
+	}
+
+	var buf bytes.Buffer
+	err := rd.tmpl.Execute(&buf, Page{
+		Brand:   rd.brand,
+		Status:  status,
+		Title:   http.StatusText(status),
+		Message: message,
+	})
+	if err != nil {
+		http.Error(w, message, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+func WantsHTML(r *http.Request) bool {
+	for _, item := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+
+		switch mediaType {
+		case "text/html", "application/xhtml+xml":
+			return true
+		case "application/json", "*/*":
+			return false
+		}
+	}
+
+	return false
+}
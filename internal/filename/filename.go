@@ -0,0 +1,89 @@
+package filename
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	MaxRunes  = 255
+	KeyPrefix = "uploads/"
+)
+
+var ErrInvalidName = errors.New("invalid filename")
+
+func Normalize(name string) (string, error) {
+	if !utf8.ValidString(name) {
+		return "", fmt.Errorf("%w: not valid utf-8", ErrInvalidName)
+	}
+
+	name = norm.NFC.String(strings.TrimSpace(name))
+
+	if name == "" || name == "." || name == ".." {
+		return "", fmt.Errorf("%w: empty name", ErrInvalidName)
+	}
+	if strings.ContainsAny(name, "/\\") {
+		return "", fmt.Errorf("%w: contains a path separator", ErrInvalidName)
+	}
+	if strings.ContainsFunc(name, unicode.IsControl) {
+		return "", fmt.Errorf("%w: contains control characters", ErrInvalidName)
+	}
+	if strings.ContainsFunc(name, isBidiControl) {
+		return "", fmt.Errorf("%w: contains bidirectional control characters", ErrInvalidName)
+	}
+	if utf8.RuneCountInString(name) > MaxRunes {
+		return "", fmt.Errorf("%w: longer than %d characters", ErrInvalidName, MaxRunes)
+	}
+
+	return name, nil
+}
+
+func StorageKey(name string, now time.Time) string {
+	key := fmt.Sprintf("%s%s/%s", KeyPrefix, now.UTC().Format("2006/01/02"), uuid.New().String())
+
+	if ext := strings.ToLower(path.Ext(name)); isSafeExt(ext) {
+		key += ext
+	}
+
+	return key
+}
+
+func ContentDisposition(disposition, name string) string {
+	return mime.FormatMediaType(disposition, map[string]string{"filename": name})
+}
+
+func isSafeExt(ext string) bool {
+	if len(ext) < 2 || len(ext) > 16 {
+		return false
+	}
+
+	for _, r := range ext[1:] {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isBidiControl(r rune) bool {
+	switch {
+	case r == '\u061C', r == '\u200E', r == '\u200F':
+		return true
+	case r >= '\u202A' && r <= '\u202E':
+		return true
+	case r >= '\u2066' && r <= '\u2069':
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,78 @@
+package filetype
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const (
+	exifOrientationTag = 0x0112
+	exifTypeShort      = 3
+)
+
+var exifHeader = []byte("Exif\x00\x00")
+
+func orientationOnly(tiff []byte) []byte {
+	tiff = bytes.TrimPrefix(tiff, exifHeader)
+	if len(tiff) < 8 {
+		return nil
+	}
+
+	var order interface {
+		binary.ByteOrder
+		binary.AppendByteOrder
+	}
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return nil
+	}
+
+	count := int(order.Uint16(tiff[offset:]))
+	for i := range count {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return nil
+		}
+
+		if order.Uint16(tiff[entry:]) != exifOrientationTag {
+			continue
+		}
+		if order.Uint16(tiff[entry+2:]) != exifTypeShort || order.Uint32(tiff[entry+4:]) != 1 {
+			return nil
+		}
+
+		orientation := order.Uint16(tiff[entry+8:])
+		if orientation < 2 || orientation > 8 {
+			return nil
+		}
+
+		return orientationTIFF(order, tiff[:2], orientation)
+	}
+
+	return nil
+}
+
+func orientationTIFF(order binary.AppendByteOrder, marker []byte, orientation uint16) []byte {
+	out := make([]byte, 0, 26)
+	out = append(out, marker...)
+	out = order.AppendUint16(out, 42)
+	out = order.AppendUint32(out, 8)
+	out = order.AppendUint16(out, 1)
+	out = order.AppendUint16(out, exifOrientationTag)
+	out = order.AppendUint16(out, exifTypeShort)
+	out = order.AppendUint32(out, 1)
+	out = order.AppendUint16(out, orientation)
+	out = order.AppendUint16(out, 0)
+	out = order.AppendUint32(out, 0)
+
+	return out
+}
@@ -0,0 +1,59 @@
+package filetype
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/config"
+)
+
+const sniffLen = 512
+
+var (
+	ErrTypeNotAllowed = errors.New("file type not allowed")
+	ErrFileTooLarge   = errors.New("file too large")
+)
+
+func Detect(r io.Reader) (string, io.Reader, error) {
+	head := make([]byte, sniffLen)
+
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", nil, fmt.Errorf("failed to read file header: %w", err)
+	}
+	head = head[:n]
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse detected content type: %w", err)
+	}
+
+	mediaType = sniffVideo(head, mediaType)
+
+	return mediaType, io.MultiReader(bytes.NewReader(head), r), nil
+}
+
+func DetectAllowed(r io.Reader, app config.AppConfig) (string, io.Reader, error) {
+	mediaType, body, err := Detect(r)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !app.IsAllowedType(mediaType) {
+		return "", nil, fmt.Errorf("%w: %s", ErrTypeNotAllowed, mediaType)
+	}
+
+	return mediaType, body, nil
+}
+
+func CheckSize(app config.AppConfig, mediaType string, size int64) error {
+	if limit := app.MaxFileSizeFor(mediaType); size > limit {
+		return fmt.Errorf("%w: %s files are limited to %d bytes", ErrFileTooLarge, mediaType, limit)
+	}
+
+	return nil
+}
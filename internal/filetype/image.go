@@ -0,0 +1,85 @@
+package filetype
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+const MaxImagePixels = 50_000_000
+
+var (
+	ErrCorruptImage  = errors.New("corrupt or truncated image")
+	ErrImageTooLarge = errors.New("image dimensions too large")
+)
+
+var jpegEOI = []byte{0xFF, 0xD9}
+
+func IsImage(mediaType string) bool {
+	switch mediaType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	default:
+		return false
+	}
+}
+
+func DecodeImage(data []byte) (image.Image, string, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrCorruptImage, err)
+	}
+
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, "", fmt.Errorf("%w: invalid dimensions %dx%d", ErrCorruptImage, cfg.Width, cfg.Height)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxImagePixels {
+		return nil, "", fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrImageTooLarge, cfg.Width, cfg.Height, MaxImagePixels)
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrCorruptImage, err)
+	}
+
+	return img, format, nil
+}
+
+func ValidateImage(data []byte) error {
+	_, _, err := DecodeImage(data)
+
+	return err
+}
+
+func CheckImage(data []byte, mediaType string, repair bool) ([]byte, error) {
+	err := ValidateImage(data)
+	if err == nil || !repair || mediaType != "image/jpeg" || errors.Is(err, ErrImageTooLarge) {
+		return data, err
+	}
+
+	repaired, repairErr := RepairJPEG(data)
+	if repairErr != nil {
+		return data, err
+	}
+
+	return repaired, nil
+}
+
+func RepairJPEG(data []byte) ([]byte, error) {
+	if bytes.HasSuffix(data, jpegEOI) {
+		return nil, fmt.Errorf("%w: jpeg is not recoverable", ErrCorruptImage)
+	}
+
+	repaired := append(bytes.Clone(data), jpegEOI...)
+	if err := ValidateImage(repaired); err != nil {
+		return nil, err
+	}
+
+	return repaired, nil
+}
@@ -0,0 +1,245 @@
+package filetype
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/config"
+)
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+	pngMetadataChunks = map[string]bool{
+		"eXIf": true,
+		"tEXt": true,
+		"zTXt": true,
+		"iTXt": true,
+		"tIME": true,
+	}
+)
+
+const (
+	webpFlagXMP  = 0x04
+	webpFlagEXIF = 0x08
+)
+
+func StripMetadata(data []byte, mediaType string) ([]byte, error) {
+	switch mediaType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	default:
+		return data, nil
+	}
+}
+
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, fmt.Errorf("%w: missing jpeg start of image", ErrCorruptImage)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	keptOrientation := false
+
+	for pos := 2; ; {
+		if pos+2 > len(data) || data[pos] != 0xFF {
+			return nil, fmt.Errorf("%w: malformed jpeg segment at offset %d", ErrCorruptImage, pos)
+		}
+
+		marker := data[pos+1]
+		if marker == 0xFF {
+			pos++
+			continue
+		}
+		if marker == 0xD9 {
+			return append(out, 0xFF, 0xD9), nil
+		}
+		if pos+4 > len(data) {
+			return nil, fmt.Errorf("%w: malformed jpeg segment at offset %d", ErrCorruptImage, pos)
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, fmt.Errorf("%w: truncated jpeg segment at offset %d", ErrCorruptImage, pos)
+		}
+
+		if marker == 0xDA {
+			next := jpegScanEnd(data, end)
+			if next < 0 {
+				return append(out, data[pos:]...), nil
+			}
+			out = append(out, data[pos:next]...)
+			pos = next
+			continue
+		}
+
+		payload := data[pos+4 : end]
+		switch {
+		case !isJPEGMetadata(marker, payload):
+			out = append(out, data[pos:end]...)
+		case marker == 0xE1 && bytes.HasPrefix(payload, exifHeader) && !keptOrientation:
+			if tiff := orientationOnly(payload); tiff != nil {
+				out = append(out, 0xFF, 0xE1)
+				out = binary.BigEndian.AppendUint16(out, uint16(2+len(exifHeader)+len(tiff)))
+				out = append(out, exifHeader...)
+				out = append(out, tiff...)
+				keptOrientation = true
+			}
+		}
+		pos = end
+	}
+}
+
+func jpegScanEnd(data []byte, pos int) int {
+	for ; pos+1 < len(data); pos++ {
+		if data[pos] != 0xFF {
+			continue
+		}
+
+		switch next := data[pos+1]; {
+		case next == 0x00, next == 0xFF:
+		case next >= 0xD0 && next <= 0xD7:
+			pos++
+		default:
+			return pos
+		}
+	}
+
+	return -1
+}
+
+func isJPEGMetadata(marker byte, payload []byte) bool {
+	switch {
+	case marker == 0xFE:
+		return true
+	case marker == 0xE0:
+		return false
+	case marker == 0xE2:
+		return !bytes.HasPrefix(payload, []byte("ICC_PROFILE\x00"))
+	case marker == 0xEE:
+		return !bytes.HasPrefix(payload, []byte("Adobe"))
+	case marker >= 0xE1 && marker <= 0xEF:
+		return true
+	default:
+		return false
+	}
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, fmt.Errorf("%w: missing png signature", ErrCorruptImage)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+
+	for pos := len(pngSignature); pos < len(data); {
+		if pos+12 > len(data) {
+			return nil, fmt.Errorf("%w: truncated png chunk at offset %d", ErrCorruptImage, pos)
+		}
+
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if end > len(data) {
+			return nil, fmt.Errorf("%w: truncated png chunk at offset %d", ErrCorruptImage, pos)
+		}
+
+		chunkType := string(data[pos+4 : pos+8])
+		switch {
+		case !pngMetadataChunks[chunkType]:
+			out = append(out, data[pos:end]...)
+		case chunkType == "eXIf":
+			if tiff := orientationOnly(data[pos+8 : end-4]); tiff != nil {
+				out = appendPNGChunk(out, chunkType, tiff)
+			}
+		}
+		pos = end
+	}
+
+	return out, nil
+}
+
+func appendPNGChunk(out []byte, chunkType string, payload []byte) []byte {
+	start := len(out)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(payload)))
+	out = append(out, chunkType...)
+	out = append(out, payload...)
+
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out[start+4:]))
+}
+
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, fmt.Errorf("%w: missing webp header", ErrCorruptImage)
+	}
+
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+
+	flags := -1
+	keptOrientation := false
+
+	for pos := 12; pos < len(data); {
+		if pos+8 > len(data) {
+			return nil, fmt.Errorf("%w: truncated webp chunk at offset %d", ErrCorruptImage, pos)
+		}
+
+		fourCC := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2
+		if end > len(data) {
+			return nil, fmt.Errorf("%w: truncated webp chunk at offset %d", ErrCorruptImage, pos)
+		}
+
+		switch fourCC {
+		case "XMP ":
+		case "EXIF":
+			if tiff := orientationOnly(data[pos+8 : pos+8+size]); tiff != nil && !keptOrientation {
+				out = append(out, fourCC...)
+				out = binary.LittleEndian.AppendUint32(out, uint32(len(tiff)))
+				out = append(out, tiff...)
+				keptOrientation = true
+			}
+		case "VP8X":
+			if size > 0 {
+				flags = len(out) + 8
+			}
+			out = append(out, data[pos:end]...)
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+
+	if flags >= 0 {
+		out[flags] &^= webpFlagEXIF | webpFlagXMP
+		if keptOrientation {
+			out[flags] |= webpFlagEXIF
+		}
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+
+	return out, nil
+}
+
+func PrepareImage(data []byte, mediaType string, app config.AppConfig) ([]byte, error) {
+	data, err := CheckImage(data, mediaType, app.RepairJPEG)
+	if err != nil {
+		return nil, err
+	}
+
+	if !app.StripImageMetadata {
+		return data, nil
+	}
+
+	return StripMetadata(data, mediaType)
+}
@@ -0,0 +1,310 @@
+package filetype
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+var gpsMarker = []byte("GPS-37.7749N-122.4194W")
+
+type byteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+func exifTIFF(order byteOrder, orientation uint16) []byte {
+	marker := "II"
+	if order == binary.BigEndian {
+		marker = "MM"
+	}
+
+	var entries [][3]uint32
+	if orientation != 0 {
+		entries = append(entries, [3]uint32{exifOrientationTag, exifTypeShort, uint32(orientation)})
+	}
+	entries = append(entries, [3]uint32{0x8825, 4, 0})
+
+	tiff := []byte(marker)
+	tiff = order.AppendUint16(tiff, 42)
+	tiff = order.AppendUint32(tiff, 8)
+	tiff = order.AppendUint16(tiff, uint16(len(entries)))
+	gpsOffset := uint32(8 + 2 + 12*len(entries) + 4)
+	for _, e := range entries {
+		tiff = order.AppendUint16(tiff, uint16(e[0]))
+		tiff = order.AppendUint16(tiff, uint16(e[1]))
+		tiff = order.AppendUint32(tiff, 1)
+		if e[0] == exifOrientationTag {
+			tiff = order.AppendUint16(tiff, uint16(e[2]))
+			tiff = order.AppendUint16(tiff, 0)
+		} else {
+			tiff = order.AppendUint32(tiff, gpsOffset)
+		}
+	}
+	tiff = order.AppendUint32(tiff, 0)
+
+	return append(tiff, gpsMarker...)
+}
+
+func jpegSegment(marker byte, payload []byte) []byte {
+	seg := []byte{0xFF, marker}
+	seg = binary.BigEndian.AppendUint16(seg, uint16(len(payload)+2))
+
+	return append(seg, payload...)
+}
+
+func encodeJPEG(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	for x := range 16 {
+		img.Set(x, x%8, color.RGBA{R: 200, A: 255})
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func jpegWithSegments(t *testing.T, segments ...[]byte) []byte {
+	t.Helper()
+
+	base := encodeJPEG(t)
+	data := append([]byte{}, base[:2]...)
+	for _, seg := range segments {
+		data = append(data, seg...)
+	}
+
+	return append(data, base[2:]...)
+}
+
+func TestStripJPEG(t *testing.T) {
+	icc := jpegSegment(0xE2, append([]byte("ICC_PROFILE\x00\x01\x01"), "profile"...))
+	adobe := jpegSegment(0xEE, []byte("Adobe\x00\x64\x00\x00\x00\x00\x01"))
+	data := jpegWithSegments(t,
+		jpegSegment(0xE1, append(append([]byte{}, exifHeader...), exifTIFF(binary.LittleEndian, 6)...)),
+		jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>secret</x:xmpmeta>")),
+		jpegSegment(0xFE, []byte("comment with author name")),
+		icc,
+		adobe,
+	)
+
+	out, err := StripMetadata(data, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, leaked := range [][]byte{gpsMarker, []byte("xmpmeta"), []byte("author name")} {
+		if bytes.Contains(out, leaked) {
+			t.Fatalf("stripped jpeg still contains %q", leaked)
+		}
+	}
+	if !bytes.Contains(out, icc) || !bytes.Contains(out, adobe) {
+		t.Fatal("ICC profile or Adobe segment was dropped")
+	}
+
+	want := jpegSegment(0xE1, append(append([]byte{}, exifHeader...), orientationTIFF(binary.LittleEndian, []byte("II"), 6)...))
+	if !bytes.Contains(out, want) {
+		t.Fatal("orientation-only Exif segment missing")
+	}
+
+	if _, err := jpeg.Decode(bytes.NewReader(out)); err != nil {
+		t.Fatalf("stripped jpeg does not decode: %v", err)
+	}
+}
+
+func TestStripJPEGDropsExifWithoutOrientation(t *testing.T) {
+	data := jpegWithSegments(t, jpegSegment(0xE1, append(append([]byte{}, exifHeader...), exifTIFF(binary.BigEndian, 0)...)))
+
+	out, err := StripMetadata(data, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, exifHeader) {
+		t.Fatal("Exif segment kept although it carries no orientation")
+	}
+}
+
+func TestStripJPEGDropsTrailingImages(t *testing.T) {
+	primary := jpegWithSegments(t)
+	preview := jpegWithSegments(t, jpegSegment(0xE1, append(append([]byte{}, exifHeader...), exifTIFF(binary.LittleEndian, 1)...)))
+
+	data := append(append([]byte{}, primary...), preview...)
+	data = append(data, []byte("vendor trailer with GPS-37.7749N-122.4194W")...)
+
+	out, err := StripMetadata(data, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(out, gpsMarker) {
+		t.Fatal("embedded preview or trailer leaked GPS data")
+	}
+	if !bytes.Equal(out, primary) {
+		t.Fatalf("got %d bytes, want the %d-byte primary image", len(out), len(primary))
+	}
+}
+
+func pngChunk(chunkType string, payload []byte) []byte {
+	return appendPNGChunk(nil, chunkType, payload)
+}
+
+func TestStripPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	base := buf.Bytes()
+	ihdrEnd := len(pngSignature) + 12 + 13
+
+	iccp := pngChunk("iCCP", []byte("profile\x00\x00compressed"))
+	data := append([]byte{}, base[:ihdrEnd]...)
+	data = append(data, iccp...)
+	data = append(data, pngChunk("eXIf", exifTIFF(binary.BigEndian, 8))...)
+	data = append(data, pngChunk("tEXt", []byte("Author\x00someone"))...)
+	data = append(data, pngChunk("tIME", []byte{0x07, 0xE8, 1, 2, 3, 4, 5})...)
+	data = append(data, base[ihdrEnd:]...)
+
+	out, err := StripMetadata(data, "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(out, gpsMarker) || bytes.Contains(out, []byte("tEXt")) || bytes.Contains(out, []byte("tIME")) {
+		t.Fatal("stripped png still carries metadata")
+	}
+	if !bytes.Contains(out, iccp) {
+		t.Fatal("iCCP chunk was dropped")
+	}
+
+	want := pngChunk("eXIf", orientationTIFF(binary.BigEndian, []byte("MM"), 8))
+	if !bytes.Contains(out, want) {
+		t.Fatal("orientation-only eXIf chunk missing")
+	}
+	if crc := binary.BigEndian.Uint32(want[len(want)-4:]); crc != crc32.ChecksumIEEE(want[4:len(want)-4]) {
+		t.Fatal("eXIf chunk has a bad CRC")
+	}
+
+	if _, err := png.Decode(bytes.NewReader(out)); err != nil {
+		t.Fatalf("stripped png does not decode: %v", err)
+	}
+}
+
+func webpChunk(fourCC string, payload []byte) []byte {
+	chunk := append([]byte(fourCC), binary.LittleEndian.AppendUint32(nil, uint32(len(payload)))...)
+	chunk = append(chunk, payload...)
+	if len(payload)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+
+	return chunk
+}
+
+func webpFile(chunks ...[]byte) []byte {
+	body := []byte("WEBP")
+	for _, chunk := range chunks {
+		body = append(body, chunk...)
+	}
+
+	return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+}
+
+func TestStripWebP(t *testing.T) {
+	const webpFlagICC = 0x20
+
+	vp8x := webpChunk("VP8X", []byte{webpFlagICC | webpFlagEXIF | webpFlagXMP, 0, 0, 0, 15, 0, 0, 7, 0, 0})
+	iccp := webpChunk("ICCP", []byte("profile"))
+	bitstream := webpChunk("VP8L", []byte("\x2f\x00\x00\x00\x00"))
+
+	tests := []struct {
+		name        string
+		orientation uint16
+		wantEXIF    bool
+	}{
+		{"keeps orientation", 6, true},
+		{"drops exif without rotation", 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exif := webpChunk("EXIF", append(append([]byte{}, exifHeader...), exifTIFF(binary.LittleEndian, tt.orientation)...))
+			xmp := webpChunk("XMP ", []byte("<x:xmpmeta>secret</x:xmpmeta>"))
+			data := webpFile(vp8x, iccp, bitstream, exif, xmp)
+
+			out, err := StripMetadata(data, "image/webp")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if size := binary.LittleEndian.Uint32(out[4:]); int(size) != len(out)-8 {
+				t.Fatalf("RIFF size = %d, want %d", size, len(out)-8)
+			}
+			if bytes.Contains(out, gpsMarker) || bytes.Contains(out, []byte("xmpmeta")) {
+				t.Fatal("stripped webp still carries metadata")
+			}
+			if !bytes.Contains(out, iccp) || !bytes.Contains(out, bitstream) {
+				t.Fatal("ICC profile or image data was dropped")
+			}
+
+			flags := out[20]
+			if flags&webpFlagICC == 0 || flags&webpFlagXMP != 0 {
+				t.Fatalf("VP8X flags = %#x, want ICC set and XMP cleared", flags)
+			}
+			if (flags&webpFlagEXIF != 0) != tt.wantEXIF {
+				t.Fatalf("VP8X EXIF flag = %v, want %v", flags&webpFlagEXIF != 0, tt.wantEXIF)
+			}
+
+			want := webpChunk("EXIF", orientationTIFF(binary.LittleEndian, []byte("II"), tt.orientation))
+			if bytes.Contains(out, want) != tt.wantEXIF {
+				t.Fatalf("orientation-only EXIF chunk present = %v, want %v", !tt.wantEXIF, tt.wantEXIF)
+			}
+		})
+	}
+}
+
+func TestOrientationOnly(t *testing.T) {
+	bad := exifTIFF(binary.LittleEndian, 6)
+	binary.LittleEndian.PutUint16(bad[12:], 4)
+
+	tests := []struct {
+		name string
+		tiff []byte
+		want uint16
+	}{
+		{"little endian", exifTIFF(binary.LittleEndian, 3), 3},
+		{"big endian", exifTIFF(binary.BigEndian, 8), 8},
+		{"exif prefix", append(append([]byte{}, exifHeader...), exifTIFF(binary.BigEndian, 5)...), 5},
+		{"no rotation", exifTIFF(binary.LittleEndian, 1), 0},
+		{"out of range", exifTIFF(binary.LittleEndian, 9), 0},
+		{"missing tag", exifTIFF(binary.LittleEndian, 0), 0},
+		{"wrong type", bad, 0},
+		{"truncated", exifTIFF(binary.LittleEndian, 6)[:14], 0},
+		{"bad byte order", []byte("XX\x2a\x00\x08\x00\x00\x00"), 0},
+	}
+
+	for _, tt := range tests {
+		got := orientationOnly(tt.tiff)
+		if tt.want == 0 {
+			if got != nil {
+				t.Errorf("%s: got %x, want nil", tt.name, got)
+			}
+			continue
+		}
+
+		order := binary.ByteOrder(binary.LittleEndian)
+		if bytes.HasPrefix(bytes.TrimPrefix(tt.tiff, exifHeader), []byte("MM")) {
+			order = binary.BigEndian
+		}
+		if len(got) != 26 || order.Uint16(got[18:]) != tt.want || order.Uint16(got[10:]) != exifOrientationTag {
+			t.Errorf("%s: got %x, want an orientation-only TIFF with value %d", tt.name, got, tt.want)
+		}
+	}
+}
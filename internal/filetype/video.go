@@ -0,0 +1,48 @@
+package filetype
+
+import (
+	"bytes"
+	"strings"
+)
+
+var (
+	ebmlMagic       = []byte{0x1A, 0x45, 0xDF, 0xA3}
+	matroskaDocType = []byte("matroska")
+
+	ftypBrands = map[string]string{
+		"qt  ": "video/quicktime",
+		"M4V ": "video/x-m4v",
+		"M4VH": "video/x-m4v",
+		"M4VP": "video/x-m4v",
+		"3gp4": "video/3gpp",
+		"3gp5": "video/3gpp",
+		"3gp6": "video/3gpp",
+		"3gg6": "video/3gpp",
+		"3g2a": "video/3gpp2",
+		"3g2b": "video/3gpp2",
+		"3g2c": "video/3gpp2",
+		"isom": "video/mp4",
+		"iso2": "video/mp4",
+		"avc1": "video/mp4",
+		"mp41": "video/mp4",
+		"mp42": "video/mp4",
+	}
+)
+
+func IsVideo(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "video/")
+}
+
+func sniffVideo(head []byte, detected string) string {
+	if len(head) >= 12 && string(head[4:8]) == "ftyp" {
+		if mediaType, ok := ftypBrands[string(head[8:12])]; ok {
+			return mediaType
+		}
+	}
+
+	if bytes.HasPrefix(head, ebmlMagic) && bytes.Contains(head, matroskaDocType) {
+		return "video/x-matroska"
+	}
+
+	return detected
+}
@@ -18,13 +18,17 @@ const (
 
 type Logger struct {
 	*slog.Logger
+	level *slog.LevelVar
 }
 
 func New(env string, level slog.Level) *Logger {
 	var handler slog.Handler
 
+	levelVar := &slog.LevelVar{}
+	levelVar.Set(level)
+
 	opts := &slog.HandlerOptions{
-		Level: level,
+		Level: levelVar,
 		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
 			if env == "development" && a.Key == "time" {
 				return slog.Attr{}
@@ -42,7 +46,11 @@ func New(env string, level slog.Level) *Logger {
 
 	logger := slog.New(handler)
 
-	return &Logger{logger}
+	return &Logger{Logger: logger, level: levelVar}
+}
+
+func (l *Logger) SetLevel(level slog.Level) {
+	l.level.Set(level)
 }
 
 func (l *Logger) WithRequestID(ctx context.Context) (context.Context, *Logger) {
@@ -50,7 +58,7 @@ func (l *Logger) WithRequestID(ctx context.Context) (context.Context, *Logger) {
 
 	ctx = context.WithValue(ctx, RequestIDKey, requestID)
 
-	return ctx, &Logger{l.With(slog.String("request_id", requestID))}
+	return ctx, &Logger{Logger: l.With(slog.String("request_id", requestID)), level: l.level}
 }
 
 func GetRequestID(ctx context.Context) string {
@@ -81,11 +89,11 @@ func (l *Logger) Fatal(msg string, err error, attrs ...slog.Attr) {
 }
 
 func (l *Logger) WithComponent(component string) *Logger {
-	return &Logger{l.With(slog.String("component", component))}
+	return &Logger{Logger: l.With(slog.String("component", component)), level: l.level}
 }
 
 func (l *Logger) WithOperation(operation string) *Logger {
-	return &Logger{l.With(slog.String("operation", operation))}
+	return &Logger{Logger: l.With(slog.String("operation", operation)), level: l.level}
 }
 
 func (l *Logger) TimeTrack(start time.Time, name string, attrs ...slog.Attr) {
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	StageTimerKey ctxKey = "stage_timer"
+)
+
+type StageTimer struct {
+	mu     sync.Mutex
+	order  []string
+	stages map[string]time.Duration
+}
+
+func NewStageTimer() *StageTimer {
+	return &StageTimer{stages: make(map[string]time.Duration)}
+}
+
+func WithStageTimer(ctx context.Context) (context.Context, *StageTimer) {
+	timer := NewStageTimer()
+
+	return context.WithValue(ctx, StageTimerKey, timer), timer
+}
+
+func GetStageTimer(ctx context.Context) *StageTimer {
+	if timer, ok := ctx.Value(StageTimerKey).(*StageTimer); ok {
+		return timer
+	}
+
+	return nil
+}
+
+func TrackStage(ctx context.Context, stage string) func() {
+	timer := GetStageTimer(ctx)
+	if timer == nil {
+		return func() {}
+	}
+
+	return timer.Track(stage)
+}
+
+func (t *StageTimer) Track(stage string) func() {
+	start := time.Now()
+
+	return func() {
+		t.Add(stage, time.Since(start))
+	}
+}
+
+func (t *StageTimer) Add(stage string, elapsed time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.stages[stage]; !ok {
+		t.order = append(t.order, stage)
+	}
+	t.stages[stage] += elapsed
+}
+
+func (t *StageTimer) Attr() slog.Attr {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	attrs := make([]any, 0, len(t.order))
+	for _, stage := range t.order {
+		attrs = append(attrs, slog.Duration(stage, t.stages[stage]))
+	}
+
+	return slog.Group("stages", attrs...)
+}
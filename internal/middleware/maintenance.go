@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/config"
+)
+
+type MaintenanceSwitch struct {
+	mode    atomic.Value
+	message string
+}
+
+func NewMaintenanceSwitch(mode, message string) *MaintenanceSwitch {
+	s := &MaintenanceSwitch{message: message}
+	s.mode.Store(mode)
+
+	return s
+}
+
+func (s *MaintenanceSwitch) Mode() string {
+	return s.mode.Load().(string)
+}
+
+func (s *MaintenanceSwitch) SetMode(mode string) error {
+	if !config.IsValidServiceMode(mode) {
+		return fmt.Errorf("invalid service mode %q", mode)
+	}
+
+	s.mode.Store(mode)
+
+	return nil
+}
+
+func (s *MaintenanceSwitch) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch s.Mode() {
+		case config.ServiceModeMaintenance:
+			s.reject(w)
+			return
+		case config.ServiceModeReadOnly:
+			if !isReadMethod(r.Method) {
+				s.reject(w)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *MaintenanceSwitch) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			var req struct {
+				Mode string `json:"mode"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid request body", http.StatusBadRequest)
+				return
+			}
+			if err := s.SetMode(req.Mode); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"mode": s.Mode()})
+	}
+}
+
+func (s *MaintenanceSwitch) reject(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": s.message,
+		"mode":  s.Mode(),
+	})
+}
+
+func isReadMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
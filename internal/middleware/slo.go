@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const sloBuckets = 60
+
+type SLOObjectives struct {
+	Availability     float64
+	Latency          float64
+	LatencyThreshold time.Duration
+}
+
+type SLOTracker struct {
+	objectives SLOObjectives
+	classify   func(*http.Request) string
+
+	mu      sync.Mutex
+	classes map[string]*sloWindow
+}
+
+type sloWindow struct {
+	buckets [sloBuckets]sloBucket
+}
+
+type sloBucket struct {
+	minute int64
+	total  uint64
+	errors uint64
+	slow   uint64
+}
+
+type SLOReport struct {
+	Class   string          `json:"class"`
+	Windows []SLOWindowStat `json:"windows"`
+}
+
+type SLOWindowStat struct {
+	Window               string  `json:"window"`
+	Requests             uint64  `json:"requests"`
+	Availability         float64 `json:"availability"`
+	LatencyCompliance    float64 `json:"latency_compliance"`
+	AvailabilityBurnRate float64 `json:"availability_burn_rate"`
+	LatencyBurnRate      float64 `json:"latency_burn_rate"`
+}
+
+func NewSLOTracker(objectives SLOObjectives, classify func(*http.Request) string) *SLOTracker {
+	if classify == nil {
+		classify = classifyByMethod
+	}
+
+	return &SLOTracker{
+		objectives: objectives,
+		classify:   classify,
+		classes:    make(map[string]*sloWindow),
+	}
+}
+
+func (t *SLOTracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		t.record(t.classify(r), rec.status, time.Since(start), start)
+	})
+}
+
+func (t *SLOTracker) record(class string, status int, elapsed time.Duration, at time.Time) {
+	minute := at.Unix() / 60
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	win, ok := t.classes[class]
+	if !ok {
+		win = &sloWindow{}
+		t.classes[class] = win
+	}
+
+	b := &win.buckets[minute%sloBuckets]
+	if b.minute != minute {
+		*b = sloBucket{minute: minute}
+	}
+
+	b.total++
+	if status >= http.StatusInternalServerError {
+		b.errors++
+	}
+	if elapsed > t.objectives.LatencyThreshold {
+		b.slow++
+	}
+}
+
+func (t *SLOTracker) Report() []SLOReport {
+	now := time.Now().Unix() / 60
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	reports := make([]SLOReport, 0, len(t.classes))
+	for class, win := range t.classes {
+		reports = append(reports, SLOReport{
+			Class: class,
+			Windows: []SLOWindowStat{
+				t.summarize(win, now, 5, "5m"),
+				t.summarize(win, now, sloBuckets, "1h"),
+			},
+		})
+	}
+
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Class < reports[j].Class })
+
+	return reports
+}
+
+func (t *SLOTracker) summarize(win *sloWindow, now int64, minutes int64, label string) SLOWindowStat {
+	var total, errors, slow uint64
+
+	for _, b := range win.buckets {
+		if b.total == 0 || now-b.minute >= minutes {
+			continue
+		}
+		total += b.total
+		errors += b.errors
+		slow += b.slow
+	}
+
+	stat := SLOWindowStat{Window: label, Requests: total, Availability: 1, LatencyCompliance: 1}
+	if total == 0 {
+		return stat
+	}
+
+	errorRate := float64(errors) / float64(total)
+	slowRate := float64(slow) / float64(total)
+
+	stat.Availability = 1 - errorRate
+	stat.LatencyCompliance = 1 - slowRate
+	stat.AvailabilityBurnRate = burnRate(errorRate, t.objectives.Availability)
+	stat.LatencyBurnRate = burnRate(slowRate, t.objectives.Latency)
+
+	return stat
+}
+
+func (t *SLOTracker) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t.Report())
+	}
+}
+
+func burnRate(badRate, target float64) float64 {
+	budget := 1 - target
+	if budget <= 0 {
+		return 0
+	}
+
+	return badRate / budget
+}
+
+func classifyByMethod(r *http.Request) string {
+	if isReadMethod(r.Method) {
+		return "read"
+	}
+
+	return "write"
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
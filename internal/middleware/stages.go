@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/logger"
+)
+
+func StageTiming(log *logger.Logger, enabled func() bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, timer := logger.WithStageTimer(r.Context())
+			start := time.Now()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+
+			if !enabled() {
+				return
+			}
+
+			log.LogAttrs(context.Background(), slog.LevelInfo, "request stages",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("request_id", logger.GetRequestID(r.Context())),
+				slog.Duration("duration", time.Since(start)),
+				timer.Attr(),
+			)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	chunkSize = 64 << 10
+
+	streamOK     = "stream: OK"
+	streamPrefix = "stream: "
+	foundSuffix  = " FOUND"
+)
+
+var (
+	ErrInfected     = errors.New("file is infected")
+	ErrScanFailed   = errors.New("virus scan failed")
+	ErrSizeExceeded = errors.New("file exceeds the scanner stream size limit")
+)
+
+type Result struct {
+	Clean     bool
+	Signature string
+}
+
+func (r Result) Err() error {
+	if r.Clean {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrInfected, r.Signature)
+}
+
+type ClamAV struct {
+	addr    string
+	timeout time.Duration
+	dialer  net.Dialer
+}
+
+func NewClamAV(addr string, timeout time.Duration) *ClamAV {
+	return &ClamAV{addr: addr, timeout: timeout}
+}
+
+func (c *ClamAV) Ping(ctx context.Context) error {
+	reply, err := c.command(ctx, "zPING\x00", nil)
+	if err != nil {
+		return err
+	}
+
+	if reply != "PONG" {
+		return fmt.Errorf("%w: unexpected ping reply %q", ErrScanFailed, reply)
+	}
+
+	return nil
+}
+
+func (c *ClamAV) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	reply, err := c.command(ctx, "zINSTREAM\x00", r)
+	if err != nil {
+		return Result{}, err
+	}
+
+	switch {
+	case reply == streamOK:
+		return Result{Clean: true}, nil
+	case strings.HasSuffix(reply, foundSuffix):
+		signature := strings.TrimSuffix(strings.TrimPrefix(reply, streamPrefix), foundSuffix)
+		return Result{Signature: signature}, nil
+	case strings.Contains(reply, "size limit exceeded"):
+		return Result{}, ErrSizeExceeded
+	default:
+		return Result{}, fmt.Errorf("%w: %s", ErrScanFailed, reply)
+	}
+}
+
+func (c *ClamAV) command(ctx context.Context, cmd string, body io.Reader) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	conn, err := c.dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to connect to clamd at %s: %v", ErrScanFailed, c.addr, err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := io.WriteString(conn, cmd); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrScanFailed, err)
+	}
+
+	var streamErr error
+	if body != nil {
+		streamErr = writeStream(conn, body)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	if reply == "" {
+		if streamErr != nil {
+			return "", streamErr
+		}
+		return "", fmt.Errorf("%w: failed to read clamd reply: %v", ErrScanFailed, err)
+	}
+
+	return reply, nil
+}
+
+func writeStream(w io.Writer, r io.Reader) error {
+	buf := make([]byte, 4+chunkSize)
+
+	for {
+		n, err := io.ReadFull(r, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, werr := w.Write(buf[:4+n]); werr != nil {
+				return fmt.Errorf("%w: failed to stream file to clamd: %v", ErrScanFailed, werr)
+			}
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read file for scanning: %w", err)
+		}
+	}
+
+	if _, err := w.Write(bytes.Repeat([]byte{0}, 4)); err != nil {
+		return fmt.Errorf("%w: failed to stream file to clamd: %v", ErrScanFailed, err)
+	}
+
+	return nil
+}
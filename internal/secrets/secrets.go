@@ -0,0 +1,88 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	SchemeSecretsManager = "secretsmanager://"
+	SchemeVault          = "vault://"
+)
+
+type Provider interface {
+	Fetch(ctx context.Context, path string) (string, error)
+}
+
+type Resolver struct {
+	providers map[string]Provider
+}
+
+func NewResolver(region string) *Resolver {
+	return &Resolver{
+		providers: map[string]Provider{
+			SchemeSecretsManager: &SecretsManager{region: region},
+			SchemeVault:          NewVaultFromEnv(),
+		},
+	}
+}
+
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, SchemeSecretsManager) || strings.HasPrefix(value, SchemeVault)
+}
+
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	for scheme, provider := range r.providers {
+		rest, ok := strings.CutPrefix(ref, scheme)
+		if !ok {
+			continue
+		}
+
+		path, field, _ := strings.Cut(rest, "#")
+
+		secret, err := provider.Fetch(ctx, path)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s%s: %w", scheme, path, err)
+		}
+
+		if field == "" {
+			if isJSONObject(secret) {
+				return "", fmt.Errorf("%s%s holds a JSON object, select a value with #field", scheme, path)
+			}
+			return secret, nil
+		}
+
+		return extractField(secret, field)
+	}
+
+	return ref, nil
+}
+
+func extractField(secret, field string) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(secret))
+	decoder.UseNumber()
+
+	var fields map[string]any
+	if err := decoder.Decode(&fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, cannot select %q", field)
+	}
+
+	value, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %q", field)
+	}
+
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	return fmt.Sprint(value), nil
+}
+
+func isJSONObject(secret string) bool {
+	var fields map[string]json.RawMessage
+
+	return json.Unmarshal([]byte(secret), &fields) == nil
+}
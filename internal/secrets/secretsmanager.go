@@ -0,0 +1,46 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+type SecretsManager struct {
+	region string
+
+	once   sync.Once
+	client *secretsmanager.Client
+	err    error
+}
+
+func (s *SecretsManager) Fetch(ctx context.Context, path string) (string, error) {
+	s.once.Do(func() {
+		cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(s.region))
+		if err != nil {
+			s.err = fmt.Errorf("failed to load aws config: %w", err)
+			return
+		}
+		s.client = secretsmanager.NewFromConfig(cfg)
+	})
+	if s.err != nil {
+		return "", s.err
+	}
+
+	out, err := s.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(path),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if out.SecretString == nil {
+		return "", fmt.Errorf("secret %s has no string value", path)
+	}
+
+	return aws.ToString(out.SecretString), nil
+}
@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+type Vault struct {
+	addr   string
+	token  string
+	client *http.Client
+}
+
+func NewVaultFromEnv() *Vault {
+	return &Vault{
+		addr:   strings.TrimRight(os.Getenv("VAULT_ADDR"), "/"),
+		token:  os.Getenv("VAULT_TOKEN"),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (v *Vault) Fetch(ctx context.Context, path string) (string, error) {
+	if v.addr == "" || v.token == "" {
+		return "", errors.New("VAULT_ADDR and VAULT_TOKEN must be set to resolve vault secrets")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.addr+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	if nested, ok := body.Data["data"]; ok {
+		return string(nested), nil
+	}
+
+	data, err := json.Marshal(body.Data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/config"
+)
+
+var ErrDecodedSizeMismatch = errors.New("decompressed object does not match its recorded size")
+
+type CompressedBackend struct {
+	inner    Backend
+	encoding string
+	cfg      config.StorageConfig
+}
+
+func NewCompressedBackend(inner Backend, cfg config.StorageConfig) *CompressedBackend {
+	encoding := config.CompressionGzip
+	if cfg.Compression == config.CompressionZstd {
+		encoding = config.CompressionZstd
+	}
+
+	return &CompressedBackend{inner: inner, encoding: encoding, cfg: cfg}
+}
+
+func (b *CompressedBackend) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string, opts ...PutOption) error {
+	if !b.cfg.IsCompressible(contentType) {
+		return b.inner.Put(ctx, key, body, size, contentType, opts...)
+	}
+
+	spool, err := os.CreateTemp("", "compress-*")
+	if err != nil {
+		return fmt.Errorf("failed to create spool file for %s: %w", key, err)
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	decodedSize, err := b.compress(spool, body)
+	if err != nil {
+		return fmt.Errorf("failed to compress object %s: %w", key, err)
+	}
+
+	compressedSize, err := spool.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to compress object %s: %w", key, err)
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to compress object %s: %w", key, err)
+	}
+
+	return b.inner.Put(ctx, key, spool, compressedSize, contentType, WithContentEncoding(b.encoding, decodedSize))
+}
+
+func (b *CompressedBackend) compress(w io.Writer, r io.Reader) (int64, error) {
+	var zw io.WriteCloser
+	var err error
+
+	switch b.encoding {
+	case config.CompressionZstd:
+		zw, err = zstd.NewWriter(w)
+		if err != nil {
+			return 0, err
+		}
+	default:
+		zw = gzip.NewWriter(w)
+	}
+
+	n, err := io.Copy(zw, r)
+	if err != nil {
+		zw.Close()
+		return 0, err
+	}
+
+	return n, zw.Close()
+}
+
+func (b *CompressedBackend) Get(ctx context.Context, key string) (*Object, error) {
+	return b.GetEncoded(ctx, key, "")
+}
+
+func (b *CompressedBackend) GetEncoded(ctx context.Context, key, acceptEncoding string) (*Object, error) {
+	obj, err := b.inner.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if obj.ContentEncoding == "" {
+		return obj, nil
+	}
+
+	if obj.ContentEncoding != config.CompressionGzip && obj.ContentEncoding != config.CompressionZstd {
+		obj.Body.Close()
+		return nil, fmt.Errorf("object %s has unknown compression encoding %q", key, obj.ContentEncoding)
+	}
+
+	if accepts(acceptEncoding, obj.ContentEncoding) {
+		return obj, nil
+	}
+
+	body, err := decompress(obj.Body, obj.ContentEncoding)
+	if err != nil {
+		obj.Body.Close()
+		return nil, fmt.Errorf("failed to decompress object %s: %w", key, err)
+	}
+
+	closer := closeFunc(func() error {
+		body.Close()
+		return obj.Body.Close()
+	})
+
+	return &Object{
+		Body:        readCloser{Reader: &exactReader{r: body, remaining: obj.DecodedSize}, Closer: closer},
+		ContentType: obj.ContentType,
+		Size:        obj.DecodedSize,
+	}, nil
+}
+
+func (b *CompressedBackend) Delete(ctx context.Context, key string) error {
+	return b.inner.Delete(ctx, key)
+}
+
+func (b *CompressedBackend) Presign(ctx context.Context, method, key string, expires time.Duration) (string, error) {
+	return b.inner.Presign(ctx, method, key, expires)
+}
+
+func (b *CompressedBackend) Unwrap() Backend {
+	return b.inner
+}
+
+func (b *CompressedBackend) Exists(ctx context.Context, key string) (bool, error) {
+	return b.inner.Exists(ctx, key)
+}
+
+func decompress(r io.Reader, encoding string) (io.ReadCloser, error) {
+	if encoding == config.CompressionZstd {
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return zr.IOReadCloser(), nil
+	}
+
+	return gzip.NewReader(r)
+}
+
+type exactReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (r *exactReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n - 1, ErrDecodedSizeMismatch
+	}
+	if errors.Is(err, io.EOF) && r.remaining > 0 {
+		return n, ErrDecodedSizeMismatch
+	}
+
+	return n, err
+}
+
+type closeFunc func() error
+
+func (f closeFunc) Close() error {
+	return f()
+}
+
+func accepts(acceptEncoding, name string) bool {
+	return slices.ContainsFunc(strings.Split(acceptEncoding, ","), func(item string) bool {
+		coding, params, _ := strings.Cut(item, ";")
+		return strings.EqualFold(strings.TrimSpace(coding), name) && strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+	})
+}
@@ -0,0 +1,310 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/config"
+)
+
+func newCompressedBackend(t *testing.T, compression string) (*CompressedBackend, string) {
+	t.Helper()
+
+	root := t.TempDir()
+	inner, err := NewLocalBackend(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewCompressedBackend(inner, config.StorageConfig{
+		Compression:   compression,
+		CompressTypes: []string{"text/*", "application/json"},
+	}), root
+}
+
+func readObject(t *testing.T, obj *Object) []byte {
+	t.Helper()
+	defer obj.Body.Close()
+
+	data, err := io.ReadAll(obj.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestCompressedBackendRoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat("compressible line of text\n", 1000))
+
+	for _, compression := range []string{config.CompressionGzip, config.CompressionZstd} {
+		t.Run(compression, func(t *testing.T) {
+			backend, root := newCompressedBackend(t, compression)
+			ctx := context.Background()
+
+			if err := backend.Put(ctx, "doc.txt", bytes.NewReader(data), int64(len(data)), "text/plain"); err != nil {
+				t.Fatal(err)
+			}
+
+			stored, err := os.ReadFile(filepath.Join(root, "doc.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stored) >= len(data) {
+				t.Fatalf("stored %d bytes, want fewer than %d", len(stored), len(data))
+			}
+
+			record, err := os.ReadFile(filepath.Join(root, "doc.txt"+contentEncodingSuffix))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := compression + " 26000"; string(record) != want {
+				t.Fatalf("encoding record = %q, want %q", record, want)
+			}
+
+			obj, err := backend.Get(ctx, "doc.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if obj.Size != int64(len(data)) || obj.ContentEncoding != "" || obj.ContentType != "text/plain" {
+				t.Fatalf("got size %d, encoding %q, type %q", obj.Size, obj.ContentEncoding, obj.ContentType)
+			}
+			if got := readObject(t, obj); !bytes.Equal(got, data) {
+				t.Fatal("round trip mismatch")
+			}
+		})
+	}
+}
+
+func TestCompressedBackendPassThrough(t *testing.T) {
+	backend, root := newCompressedBackend(t, config.CompressionGzip)
+	ctx := context.Background()
+
+	data := append([]byte("FUZ1\x01\x00\x00\x00\x00\x00\x00\x00\x05"), []byte("\x1f\x8b attacker bytes")...)
+	if err := backend.Put(ctx, "img.png", bytes.NewReader(data), int64(len(data)), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := os.ReadFile(filepath.Join(root, "img.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stored, data) {
+		t.Fatal("non-compressible object was not stored byte-for-byte")
+	}
+	if _, err := os.Stat(filepath.Join(root, "img.png"+contentEncodingSuffix)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("unexpected encoding record: %v", err)
+	}
+
+	obj, err := backend.Get(ctx, "img.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.Size != int64(len(data)) || obj.ContentEncoding != "" {
+		t.Fatalf("got size %d, encoding %q", obj.Size, obj.ContentEncoding)
+	}
+	if got := readObject(t, obj); !bytes.Equal(got, data) {
+		t.Fatal("pass-through object was altered on read")
+	}
+}
+
+func TestCompressedBackendAcceptEncoding(t *testing.T) {
+	backend, _ := newCompressedBackend(t, config.CompressionGzip)
+	ctx := context.Background()
+
+	data := []byte(strings.Repeat(`{"key":"value"}`, 500))
+	if err := backend.Put(ctx, "doc.json", bytes.NewReader(data), int64(len(data)), "application/json"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		acceptEncoding string
+		wantEncoding   string
+	}{
+		{"gzip", "gzip"},
+		{"br, GZIP;q=0.5", "gzip"},
+		{"gzip;q=0", ""},
+		{"zstd", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		obj, err := backend.GetEncoded(ctx, "doc.json", tt.acceptEncoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if obj.ContentEncoding != tt.wantEncoding {
+			t.Fatalf("Accept-Encoding %q: got encoding %q, want %q", tt.acceptEncoding, obj.ContentEncoding, tt.wantEncoding)
+		}
+
+		got := readObject(t, obj)
+		if tt.wantEncoding == "gzip" {
+			zr, err := gzip.NewReader(bytes.NewReader(got))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err = io.ReadAll(zr); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("Accept-Encoding %q: body mismatch", tt.acceptEncoding)
+		}
+	}
+}
+
+func TestCompressedBackendIgnoresLegacyIdentityHeader(t *testing.T) {
+	backend, root := newCompressedBackend(t, config.CompressionGzip)
+
+	data := []byte("FUZ1\x00\x00\x00\x00\x00\x00\x00\x00\x05hello")
+	if err := os.WriteFile(filepath.Join(root, "legacy.txt"), data, 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := backend.Get(context.Background(), "legacy.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.Size != int64(len(data)) {
+		t.Fatalf("size = %d, want %d", obj.Size, len(data))
+	}
+	if got := readObject(t, obj); !bytes.Equal(got, data) {
+		t.Fatalf("got %q, want the stored bytes unchanged", got)
+	}
+}
+
+func TestCompressedBackendRejectsSizeMismatch(t *testing.T) {
+	backend, root := newCompressedBackend(t, config.CompressionGzip)
+	ctx := context.Background()
+
+	data := []byte(strings.Repeat("a", 4096))
+	if err := backend.Put(ctx, "doc.txt", bytes.NewReader(data), int64(len(data)), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, record := range []string{"gzip 100", "gzip 5000"} {
+		if err := os.WriteFile(filepath.Join(root, "doc.txt"+contentEncodingSuffix), []byte(record), 0o640); err != nil {
+			t.Fatal(err)
+		}
+
+		obj, err := backend.Get(ctx, "doc.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = io.ReadAll(obj.Body)
+		obj.Body.Close()
+		if !errors.Is(err, ErrDecodedSizeMismatch) {
+			t.Fatalf("record %q: got %v, want ErrDecodedSizeMismatch", record, err)
+		}
+	}
+}
+
+func TestCompressedBackendOverwriteClearsRecord(t *testing.T) {
+	backend, root := newCompressedBackend(t, config.CompressionGzip)
+	ctx := context.Background()
+
+	text := []byte(strings.Repeat("text ", 100))
+	if err := backend.Put(ctx, "obj", bytes.NewReader(text), int64(len(text)), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	raw := []byte("\x1f\x8b\x08 not really gzip")
+	if err := backend.Put(ctx, "obj", bytes.NewReader(raw), int64(len(raw)), "application/octet-stream"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "obj"+contentEncodingSuffix)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("stale encoding record left behind: %v", err)
+	}
+
+	obj, err := backend.Get(ctx, "obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readObject(t, obj); !bytes.Equal(got, raw) {
+		t.Fatalf("got %q, want %q", got, raw)
+	}
+}
+
+func TestCompressedBackendOverS3(t *testing.T) {
+	fake := newFakeS3("uploads")
+	backend := NewCompressedBackend(newTestS3Backend(t, fake, config.SSEModeNone), config.StorageConfig{
+		Compression:   config.CompressionGzip,
+		CompressTypes: []string{"text/*"},
+	})
+	ctx := context.Background()
+
+	data := []byte(strings.Repeat("log line\n", 2000))
+	if err := backend.Put(ctx, "a/log.txt", bytes.NewReader(data), int64(len(data)), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	headers := fake.headers["a/log.txt"]
+	if !strings.Contains(headers.Get("Content-Encoding"), "gzip") {
+		t.Fatalf("Content-Encoding = %q, want gzip", headers.Get("Content-Encoding"))
+	}
+	if got := headers.Get("X-Amz-Meta-Decoded-Size"); got != "18000" {
+		t.Fatalf("decoded size metadata = %q, want 18000", got)
+	}
+
+	obj, err := backend.Get(ctx, "a/log.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.Size != int64(len(data)) {
+		t.Fatalf("size = %d, want %d", obj.Size, len(data))
+	}
+	if got := readObject(t, obj); !bytes.Equal(got, data) {
+		t.Fatal("round trip mismatch")
+	}
+
+	url, err := backend.Presign(ctx, http.MethodGet, "a/log.txt", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Uncompressed || !bytes.Equal(got, data) {
+		t.Fatalf("presigned GET was not decoded by the client (uncompressed=%v, %d bytes)", resp.Uncompressed, len(got))
+	}
+}
+
+func TestCompressedBackendIgnoresUnrecordedS3Encoding(t *testing.T) {
+	fake := newFakeS3("uploads")
+	backend := NewCompressedBackend(newTestS3Backend(t, fake, config.SSEModeNone), config.StorageConfig{
+		Compression:   config.CompressionGzip,
+		CompressTypes: []string{"text/*"},
+	})
+
+	data := []byte("\x1f\x8b\x08\x00 user supplied")
+	fake.objects["a/upload.bin"] = data
+	fake.headers["a/upload.bin"] = http.Header{"Content-Encoding": {"gzip"}}
+
+	obj, err := backend.Get(context.Background(), "a/upload.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.ContentEncoding != "" {
+		t.Fatalf("encoding = %q, want none without the decoded-size record", obj.ContentEncoding)
+	}
+	if got := readObject(t, obj); !bytes.Equal(got, data) {
+		t.Fatalf("got %q, want %q", got, data)
+	}
+}
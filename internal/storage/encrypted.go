@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/encryption"
+)
+
+var (
+	ErrNotEncrypted = errors.New("object is not in the encrypted format")
+
+	encryptedMagic = []byte("FUE1")
+)
+
+const encryptedHeaderSize = 4 + 2 + encryption.WrappedKeySize + encryption.NonceSize
+
+type EncryptedBackend struct {
+	inner    Backend
+	envelope *encryption.Envelope
+}
+
+func NewEncryptedBackend(inner Backend, masterKey []byte) (*EncryptedBackend, error) {
+	envelope, err := encryption.NewEnvelope(masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise envelope encryption: %w", err)
+	}
+
+	return &EncryptedBackend{inner: inner, envelope: envelope}, nil
+}
+
+func (b *EncryptedBackend) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string, opts ...PutOption) error {
+	dataKey, wrapped, err := b.envelope.NewDataKey()
+	if err != nil {
+		return err
+	}
+
+	nonce, err := encryption.NewNonce()
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := encryption.NewEncryptReader(body, dataKey, nonce)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt object %s: %w", key, err)
+	}
+
+	header := make([]byte, 0, encryptedHeaderSize)
+	header = append(header, encryptedMagic...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(wrapped)))
+	header = append(header, wrapped...)
+	header = append(header, nonce...)
+
+	body = io.MultiReader(bytes.NewReader(header), encrypted)
+	size = encryptedHeaderSize + encryption.CiphertextSize(size)
+
+	o := putOptions(opts)
+
+	return b.inner.Put(ctx, key, body, size, contentType, WithContentEncoding(o.ContentEncoding, o.DecodedSize))
+}
+
+func (b *EncryptedBackend) Get(ctx context.Context, key string) (*Object, error) {
+	obj, err := b.inner.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, encryptedHeaderSize)
+	if _, err := io.ReadFull(obj.Body, header); err != nil {
+		obj.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrNotEncrypted, key)
+	}
+
+	if string(header[:4]) != string(encryptedMagic) ||
+		int(binary.BigEndian.Uint16(header[4:6])) != encryption.WrappedKeySize {
+		obj.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrNotEncrypted, key)
+	}
+
+	wrapped := header[6 : 6+encryption.WrappedKeySize]
+	nonce := header[6+encryption.WrappedKeySize:]
+
+	dataKey, err := b.envelope.Unwrap(wrapped)
+	if err != nil {
+		obj.Body.Close()
+		return nil, fmt.Errorf("failed to decrypt object %s: %w", key, err)
+	}
+
+	decrypted, err := encryption.NewDecryptReader(obj.Body, dataKey, nonce)
+	if err != nil {
+		obj.Body.Close()
+		return nil, fmt.Errorf("failed to decrypt object %s: %w", key, err)
+	}
+
+	return &Object{
+		Body:            readCloser{Reader: decrypted, Closer: obj.Body},
+		ContentType:     obj.ContentType,
+		ContentEncoding: obj.ContentEncoding,
+		DecodedSize:     obj.DecodedSize,
+		Size:            encryption.PlaintextSize(obj.Size - encryptedHeaderSize),
+	}, nil
+}
+
+func (b *EncryptedBackend) Delete(ctx context.Context, key string) error {
+	return b.inner.Delete(ctx, key)
+}
+
+func (b *EncryptedBackend) Presign(ctx context.Context, method, key string, expires time.Duration) (string, error) {
+	return "", ErrPresignNotSupported
+}
+
+func (b *EncryptedBackend) Unwrap() Backend {
+	return b.inner
+}
+
+func (b *EncryptedBackend) Exists(ctx context.Context, key string) (bool, error) {
+	return b.inner.Exists(ctx, key)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
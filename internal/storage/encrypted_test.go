@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/encryption"
+)
+
+func newEncryptedBackend(t *testing.T) (*EncryptedBackend, string) {
+	t.Helper()
+
+	root := t.TempDir()
+	inner, err := NewLocalBackend(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := make([]byte, encryption.KeySize)
+	rand.Read(key)
+
+	backend, err := NewEncryptedBackend(inner, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return backend, root
+}
+
+func TestEncryptedBackendRoundTrip(t *testing.T) {
+	backend, root := newEncryptedBackend(t)
+	ctx := context.Background()
+
+	for _, size := range []int{0, encryption.ChunkSize, encryption.ChunkSize + 1} {
+		data := make([]byte, size)
+		rand.Read(data)
+
+		if err := backend.Put(ctx, "obj", bytes.NewReader(data), int64(size), "application/pdf"); err != nil {
+			t.Fatalf("size %d: put failed: %v", size, err)
+		}
+
+		raw, err := os.ReadFile(filepath.Join(root, "obj"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := encryptedHeaderSize + encryption.CiphertextSize(int64(size)); int64(len(raw)) != want {
+			t.Errorf("size %d: stored %d bytes, want %d", size, len(raw), want)
+		}
+
+		obj, err := backend.Get(ctx, "obj")
+		if err != nil {
+			t.Fatalf("size %d: get failed: %v", size, err)
+		}
+		got, err := io.ReadAll(obj.Body)
+		obj.Body.Close()
+		if err != nil {
+			t.Fatalf("size %d: read failed: %v", size, err)
+		}
+
+		if !bytes.Equal(got, data) {
+			t.Errorf("size %d: round trip mismatch", size)
+		}
+		if obj.Size != int64(size) {
+			t.Errorf("size %d: reported size %d", size, obj.Size)
+		}
+		if obj.ContentType != "application/pdf" {
+			t.Errorf("size %d: content type %q", size, obj.ContentType)
+		}
+	}
+}
+
+func TestEncryptedBackendHeaderFormat(t *testing.T) {
+	backend, root := newEncryptedBackend(t)
+
+	if err := backend.Put(context.Background(), "obj", bytes.NewReader([]byte("secret")), 6, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(root, "obj"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(raw[:4], encryptedMagic) {
+		t.Fatalf("magic is %q, want %q", raw[:4], encryptedMagic)
+	}
+	if n := binary.BigEndian.Uint16(raw[4:6]); int(n) != encryption.WrappedKeySize {
+		t.Fatalf("wrapped key length is %d, want %d", n, encryption.WrappedKeySize)
+	}
+	if bytes.Contains(raw, []byte("secret")) {
+		t.Fatal("plaintext found in stored object")
+	}
+}
+
+func TestEncryptedBackendRejectsBadObjects(t *testing.T) {
+	backend, root := newEncryptedBackend(t)
+	ctx := context.Background()
+
+	if err := os.WriteFile(filepath.Join(root, "plain"), []byte("not encrypted at all, just bytes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backend.Get(ctx, "plain"); !errors.Is(err, ErrNotEncrypted) {
+		t.Fatalf("expected ErrNotEncrypted, got %v", err)
+	}
+
+	if err := backend.Put(ctx, "obj", bytes.NewReader(make([]byte, 100)), 100, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(root, "obj")
+	raw, _ := os.ReadFile(path)
+
+	raw[10] ^= 0x01
+	os.WriteFile(path, raw, 0o600)
+	if _, err := backend.Get(ctx, "obj"); !errors.Is(err, encryption.ErrInvalidKey) {
+		t.Fatalf("expected ErrInvalidKey for tampered wrapped key, got %v", err)
+	}
+
+	raw[10] ^= 0x01
+	raw[len(raw)-1] ^= 0x01
+	os.WriteFile(path, raw, 0o600)
+	obj, err := backend.Get(ctx, "obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer obj.Body.Close()
+	if _, err := io.ReadAll(obj.Body); !errors.Is(err, encryption.ErrCorrupt) {
+		t.Fatalf("expected ErrCorrupt for tampered body, got %v", err)
+	}
+}
+
+func TestEncryptedBackendRefusesPresign(t *testing.T) {
+	backend, _ := newEncryptedBackend(t)
+
+	if _, err := backend.Presign(context.Background(), "GET", "obj", 0); !errors.Is(err, ErrPresignNotSupported) {
+		t.Fatalf("expected ErrPresignNotSupported, got %v", err)
+	}
+}
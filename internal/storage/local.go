@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/checksum"
+)
+
+const (
+	contentTypeSuffix     = ".content-type"
+	contentEncodingSuffix = ".content-encoding"
+)
+
+type LocalBackend struct {
+	root string
+}
+
+func NewLocalBackend(root string) (*LocalBackend, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage path %s: %w", root, err)
+	}
+
+	if err := os.MkdirAll(abs, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage path %s: %w", abs, err)
+	}
+
+	return &LocalBackend{root: abs}, nil
+}
+
+func (b *LocalBackend) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string, opts ...PutOption) error {
+	o := putOptions(opts)
+
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	var dst io.Writer = tmp
+	if o.SHA256 != nil {
+		dst = io.MultiWriter(tmp, hash)
+	}
+
+	if _, err := io.Copy(dst, body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write object %s: %w", key, err)
+	}
+
+	if o.SHA256 != nil && !bytes.Equal(hash.Sum(nil), o.SHA256) {
+		tmp.Close()
+		return fmt.Errorf("failed to write object %s: %w", key, checksum.ErrMismatch)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write object %s: %w", key, err)
+	}
+
+	if err := os.WriteFile(path+contentTypeSuffix, []byte(contentType), 0o640); err != nil {
+		return fmt.Errorf("failed to write content type for %s: %w", key, err)
+	}
+
+	if err := writeContentEncoding(path, o); err != nil {
+		return fmt.Errorf("failed to write content encoding for %s: %w", key, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store object %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (b *LocalBackend) Get(ctx context.Context, key string) (*Object, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to open object %s: %w", key, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to stat object %s: %w", key, err)
+	}
+
+	obj := &Object{
+		Body:        f,
+		ContentType: contentTypeOf(path),
+		Size:        info.Size(),
+	}
+
+	obj.ContentEncoding, obj.DecodedSize, err = contentEncodingOf(path)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read content encoding for %s: %w", key, err)
+	}
+
+	return obj, nil
+}
+
+func (b *LocalBackend) Delete(ctx context.Context, key string) error {
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete object %s: %w", key, err)
+	}
+
+	if err := os.Remove(path + contentTypeSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete content type for %s: %w", key, err)
+	}
+
+	if err := os.Remove(path + contentEncodingSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete content encoding for %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (b *LocalBackend) Presign(ctx context.Context, method, key string, expires time.Duration) (string, error) {
+	return "", ErrPresignNotSupported
+}
+
+func (b *LocalBackend) Exists(ctx context.Context, key string) (bool, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to stat object %s: %w", key, err)
+	}
+
+	return true, nil
+}
+
+func (b *LocalBackend) path(key string) (string, error) {
+	path := filepath.Join(b.root, filepath.FromSlash(key))
+	if path == b.root || !strings.HasPrefix(path, b.root+string(filepath.Separator)) || strings.HasSuffix(path, contentTypeSuffix) || strings.HasSuffix(path, contentEncodingSuffix) {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+
+	return path, nil
+}
+
+func contentTypeOf(path string) string {
+	if data, err := os.ReadFile(path + contentTypeSuffix); err == nil && len(data) > 0 {
+		return string(data)
+	}
+
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType
+	}
+
+	return "application/octet-stream"
+}
+
+func writeContentEncoding(path string, o PutOptions) error {
+	if o.ContentEncoding == "" {
+		if err := os.Remove(path + contentEncodingSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	return os.WriteFile(path+contentEncodingSuffix, fmt.Appendf(nil, "%s %d", o.ContentEncoding, o.DecodedSize), 0o640)
+}
+
+func contentEncodingOf(path string) (string, int64, error) {
+	data, err := os.ReadFile(path + contentEncodingSuffix)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", 0, nil
+		}
+		return "", 0, err
+	}
+
+	encoding, size, ok := strings.Cut(string(data), " ")
+	decodedSize, err := strconv.ParseInt(size, 10, 64)
+	if !ok || encoding == "" || err != nil {
+		return "", 0, fmt.Errorf("malformed content encoding record %q", data)
+	}
+
+	return encoding, decodedSize, nil
+}
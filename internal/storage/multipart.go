@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	MiB = 1 << 20
+	GiB = 1 << 30
+	TiB = 1 << 40
+
+	MinPartSize   = 5 * MiB
+	MaxPartSize   = 5 * GiB
+	MaxParts      = 10_000
+	MaxObjectSize = 5 * TiB
+)
+
+var ErrObjectTooLarge = errors.New("object exceeds storage provider size limit")
+
+func PartSize(objectSize, preferred int64) (int64, error) {
+	if objectSize < 0 {
+		return 0, fmt.Errorf("invalid object size %d", objectSize)
+	}
+	if objectSize > MaxObjectSize {
+		return 0, fmt.Errorf("%w: %d bytes declared, maximum is %d bytes", ErrObjectTooLarge, objectSize, int64(MaxObjectSize))
+	}
+
+	size := max(preferred, MinPartSize)
+
+	if parts := ceilDiv(objectSize, size); parts > MaxParts {
+		size = ceilDiv(objectSize, MaxParts)
+		size = ceilDiv(size, MiB) * MiB
+	}
+
+	return min(size, MaxPartSize), nil
+}
+
+func PartCount(objectSize, partSize int64) int64 {
+	if objectSize == 0 {
+		return 1
+	}
+
+	return ceilDiv(objectSize, partSize)
+}
+
+func ceilDiv(a, b int64) int64 {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/config"
+)
+
+const decodedSizeMetadata = "decoded-size"
+
+type S3Backend struct {
+	client     *s3.Client
+	presign    *s3.PresignClient
+	bucket     string
+	maxTTL     time.Duration
+	multipart  config.MultipartConfig
+	throughput *ThroughputTracker
+	sse        types.ServerSideEncryption
+	kmsKeyID   *string
+	plainHTTP  bool
+}
+
+func NewS3Backend(ctx context.Context, cfg config.AWSConfig, storageCfg config.StorageConfig) (*S3Backend, error) {
+	opts := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithRegion(cfg.Region),
+	}
+	if cfg.AccessKey != "" {
+		opts = append(opts, awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(cfg.AccessKey, cfg.SecretKey, "")))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	if _, err := awsCfg.Credentials.Retrieve(ctx); err != nil {
+		return nil, fmt.Errorf("no aws credentials: set AWS_ACCESS_KEY and AWS_SECRET_KEY or configure the default credential chain: %w", err)
+	}
+
+	endpoint := func(o *s3.Options) {
+		if url := cfg.S3EndpointURL(); url != "" {
+			o.BaseEndpoint = aws.String(url)
+		}
+		o.UsePathStyle = cfg.S3PathStyle
+	}
+
+	client := s3.NewFromConfig(awsCfg, endpoint)
+
+	presignClient := s3.NewFromConfig(awsCfg, endpoint, func(o *s3.Options) {
+		o.UseAccelerate = cfg.S3UseAccelerate
+	})
+
+	var throughput *ThroughputTracker
+	if storageCfg.Multipart.Adaptive {
+		throughput = NewThroughputTracker(storageCfg.Multipart.TargetPartTime)
+	}
+
+	return &S3Backend{
+		client:     client,
+		presign:    s3.NewPresignClient(presignClient),
+		bucket:     cfg.S3Bucket,
+		maxTTL:     storageCfg.MaxPresignTTL,
+		multipart:  storageCfg.Multipart,
+		throughput: throughput,
+		sse:        sseType(cfg.S3SSEMode),
+		kmsKeyID:   kmsKeyID(cfg),
+		plainHTTP:  strings.HasPrefix(cfg.S3EndpointURL(), "http://"),
+	}, nil
+}
+
+func sseType(mode string) types.ServerSideEncryption {
+	switch mode {
+	case config.SSEModeS3:
+		return types.ServerSideEncryptionAes256
+	case config.SSEModeKMS:
+		return types.ServerSideEncryptionAwsKms
+	default:
+		return ""
+	}
+}
+
+func kmsKeyID(cfg config.AWSConfig) *string {
+	if cfg.S3SSEMode != config.SSEModeKMS || cfg.S3KMSKeyID == "" {
+		return nil
+	}
+
+	return aws.String(cfg.S3KMSKeyID)
+}
+
+func (b *S3Backend) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string, opts ...PutOption) error {
+	o := putOptions(opts)
+
+	if size > b.multipart.Threshold {
+		return b.putMultipart(ctx, key, body, size, contentType, o)
+	}
+
+	if _, ok := body.(io.Seeker); !ok && b.plainHTTP {
+		spool, err := os.CreateTemp("", "s3-put-*")
+		if err != nil {
+			return fmt.Errorf("failed to create spool file for %s: %w", key, err)
+		}
+		defer os.Remove(spool.Name())
+		defer spool.Close()
+
+		if _, err := io.Copy(spool, body); err != nil {
+			return fmt.Errorf("failed to spool object %s: %w", key, err)
+		}
+
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to spool object %s: %w", key, err)
+		}
+		body = spool
+	}
+
+	_, err := b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:               aws.String(b.bucket),
+		Key:                  aws.String(key),
+		Body:                 body,
+		ContentLength:        aws.Int64(size),
+		ContentType:          aws.String(contentType),
+		ContentEncoding:      encodingHeader(o),
+		Metadata:             encodingMetadata(o),
+		ChecksumSHA256:       base64SHA256(o.SHA256),
+		ServerSideEncryption: b.sse,
+		SSEKMSKeyId:          b.kmsKeyID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put object %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (b *S3Backend) Get(ctx context.Context, key string) (*Object, error) {
+	out, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+
+	obj := &Object{
+		Body:        out.Body,
+		ContentType: aws.ToString(out.ContentType),
+		Size:        aws.ToInt64(out.ContentLength),
+	}
+
+	if decoded, ok := out.Metadata[decodedSizeMetadata]; ok {
+		obj.DecodedSize, err = strconv.ParseInt(decoded, 10, 64)
+		if err != nil {
+			out.Body.Close()
+			return nil, fmt.Errorf("object %s has invalid %s metadata %q", key, decodedSizeMetadata, decoded)
+		}
+		obj.ContentEncoding = aws.ToString(out.ContentEncoding)
+	}
+
+	return obj, nil
+}
+
+func base64SHA256(sum []byte) *string {
+	if sum == nil {
+		return nil
+	}
+
+	return aws.String(base64.StdEncoding.EncodeToString(sum))
+}
+
+func encodingHeader(o PutOptions) *string {
+	if o.ContentEncoding == "" {
+		return nil
+	}
+
+	return aws.String(o.ContentEncoding)
+}
+
+func encodingMetadata(o PutOptions) map[string]string {
+	if o.ContentEncoding == "" {
+		return nil
+	}
+
+	return map[string]string{decodedSizeMetadata: strconv.FormatInt(o.DecodedSize, 10)}
+}
+
+func (b *S3Backend) Delete(ctx context.Context, key string) error {
+	_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete object %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (b *S3Backend) Presign(ctx context.Context, method, key string, expires time.Duration) (string, error) {
+	var (
+		req *v4.PresignedHTTPRequest
+		err error
+	)
+
+	if expires <= 0 || expires > b.maxTTL {
+		expires = b.maxTTL
+	}
+
+	switch method {
+	case http.MethodGet:
+		req, err = b.presign.PresignGetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(b.bucket),
+			Key:    aws.String(key),
+		}, s3.WithPresignExpires(expires))
+	case http.MethodPut:
+		req, err = b.presign.PresignPutObject(ctx, &s3.PutObjectInput{
+			Bucket:               aws.String(b.bucket),
+			Key:                  aws.String(key),
+			ServerSideEncryption: b.sse,
+			SSEKMSKeyId:          b.kmsKeyID,
+		}, s3.WithPresignExpires(expires))
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedOperation, method)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to presign %s %s: %w", method, key, err)
+	}
+
+	return req.URL, nil
+}
+
+func (b *S3Backend) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to head object %s: %w", key, err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/filename"
+	"github.com/ifaisalabid1/file-upload-service/internal/logger"
+)
+
+func (b *S3Backend) putMultipart(ctx context.Context, key string, body io.Reader, size int64, contentType string, o PutOptions) error {
+	preferred := b.multipart.PartSize
+	if b.throughput != nil {
+		preferred = b.throughput.PartSize(preferred)
+	}
+
+	partSize, err := PartSize(size, preferred)
+	if err != nil {
+		return err
+	}
+
+	created, err := b.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:               aws.String(b.bucket),
+		Key:                  aws.String(key),
+		ContentType:          aws.String(contentType),
+		ContentEncoding:      encodingHeader(o),
+		Metadata:             encodingMetadata(o),
+		ChecksumAlgorithm:    checksumAlgorithm(o),
+		ServerSideEncryption: b.sse,
+		SSEKMSKeyId:          b.kmsKeyID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create multipart upload for %s: %w", key, err)
+	}
+
+	parts, err := b.uploadParts(ctx, key, created.UploadId, body, partSize, o.SHA256 != nil)
+	if err != nil {
+		b.abortMultipart(ctx, key, created.UploadId)
+		return err
+	}
+
+	_, err = b.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(b.bucket),
+		Key:             aws.String(key),
+		UploadId:        created.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		b.abortMultipart(ctx, key, created.UploadId)
+		return fmt.Errorf("failed to complete multipart upload for %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (b *S3Backend) uploadParts(ctx context.Context, key string, uploadID *string, body io.Reader, partSize int64, withChecksums bool) ([]types.CompletedPart, error) {
+	var (
+		mu    sync.Mutex
+		parts []types.CompletedPart
+	)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(b.multipart.Concurrency)
+
+	var readErr error
+	for number := int32(1); gctx.Err() == nil; number++ {
+		buf := make([]byte, partSize)
+		n, err := io.ReadFull(body, buf)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			readErr = fmt.Errorf("failed to read part %d of %s: %w", number, key, err)
+			break
+		}
+
+		g.Go(func() error {
+			var sum *string
+			if withChecksums {
+				digest := sha256.Sum256(buf[:n])
+				sum = base64SHA256(digest[:])
+			}
+
+			start := time.Now()
+			out, err := b.client.UploadPart(gctx, &s3.UploadPartInput{
+				Bucket:         aws.String(b.bucket),
+				Key:            aws.String(key),
+				UploadId:       uploadID,
+				PartNumber:     aws.Int32(number),
+				Body:           bytes.NewReader(buf[:n]),
+				ContentLength:  aws.Int64(int64(n)),
+				ChecksumSHA256: sum,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to upload part %d of %s: %w", number, key, err)
+			}
+			if b.throughput != nil {
+				b.throughput.Observe(int64(n), time.Since(start))
+			}
+
+			mu.Lock()
+			parts = append(parts, types.CompletedPart{ETag: out.ETag, PartNumber: aws.Int32(number), ChecksumSHA256: sum})
+			mu.Unlock()
+
+			return nil
+		})
+
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	slices.SortFunc(parts, func(a, b types.CompletedPart) int {
+		return int(aws.ToInt32(a.PartNumber) - aws.ToInt32(b.PartNumber))
+	})
+
+	return parts, nil
+}
+
+func checksumAlgorithm(o PutOptions) types.ChecksumAlgorithm {
+	if o.SHA256 == nil {
+		return ""
+	}
+
+	return types.ChecksumAlgorithmSha256
+}
+
+func (b *S3Backend) abortMultipart(ctx context.Context, key string, uploadID *string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+
+	b.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(b.bucket),
+		Key:      aws.String(key),
+		UploadId: uploadID,
+	})
+}
+
+func RunMultipartJanitor(ctx context.Context, backend Backend, log *logger.Logger) {
+	for {
+		switch b := backend.(type) {
+		case *S3Backend:
+			b.runMultipartJanitor(ctx, log.WithComponent("multipart-janitor"))
+			return
+		case interface{ Unwrap() Backend }:
+			backend = b.Unwrap()
+		default:
+			return
+		}
+	}
+}
+
+func (b *S3Backend) runMultipartJanitor(ctx context.Context, log *logger.Logger) {
+	if b.multipart.AbortInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(b.multipart.AbortInterval)
+	defer ticker.Stop()
+
+	for {
+		aborted, err := b.AbortStaleMultipartUploads(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error("failed to abort stale multipart uploads", err)
+		}
+		if aborted > 0 {
+			log.Info("aborted stale multipart uploads", slog.Int("count", aborted))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *S3Backend) AbortStaleMultipartUploads(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-b.multipart.AbortAfter)
+	aborted := 0
+
+	paginator := s3.NewListMultipartUploadsPaginator(b.client, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(b.bucket),
+		Prefix: aws.String(filename.KeyPrefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return aborted, fmt.Errorf("failed to list multipart uploads: %w", err)
+		}
+
+		for _, upload := range page.Uploads {
+			if upload.Initiated == nil || upload.Initiated.After(cutoff) {
+				continue
+			}
+
+			_, err := b.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+				Bucket:   aws.String(b.bucket),
+				Key:      upload.Key,
+				UploadId: upload.UploadId,
+			})
+			if err != nil {
+				return aborted, fmt.Errorf("failed to abort multipart upload for %s: %w", aws.ToString(upload.Key), err)
+			}
+			aborted++
+		}
+	}
+
+	return aborted, nil
+}
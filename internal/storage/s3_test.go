@@ -0,0 +1,394 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/config"
+	"github.com/ifaisalabid1/file-upload-service/internal/logger"
+)
+
+type fakeS3 struct {
+	mu      sync.Mutex
+	bucket  string
+	objects map[string][]byte
+	types   map[string]string
+	uploads map[string]map[int][]byte
+	pending map[string]fakeUpload
+	headers map[string]http.Header
+	nextID  int
+}
+
+type fakeUpload struct {
+	key       string
+	initiated time.Time
+}
+
+func newFakeS3(bucket string) *fakeS3 {
+	return &fakeS3{
+		bucket:  bucket,
+		objects: make(map[string][]byte),
+		types:   make(map[string]string),
+		uploads: make(map[string]map[int][]byte),
+		pending: make(map[string]fakeUpload),
+		headers: make(map[string]http.Header),
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != f.bucket {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	uploadID := query.Get("uploadId")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.nextID++
+		id := strconv.Itoa(f.nextID)
+		f.uploads[id] = make(map[int][]byte)
+		f.pending[id] = fakeUpload{key: key, initiated: time.Now()}
+		f.types[key] = r.Header.Get("Content-Type")
+		f.headers[key] = r.Header.Clone()
+		fmt.Fprintf(w, `<InitiateMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>`, bucket, key, id)
+
+	case r.Method == http.MethodGet && query.Has("uploads"):
+		io.WriteString(w, `<ListMultipartUploadsResult>`)
+		for id, upload := range f.pending {
+			if strings.HasPrefix(upload.key, query.Get("prefix")) {
+				fmt.Fprintf(w, `<Upload><Key>%s</Key><UploadId>%s</UploadId><Initiated>%s</Initiated></Upload>`, upload.key, id, upload.initiated.UTC().Format(time.RFC3339))
+			}
+		}
+		io.WriteString(w, `<IsTruncated>false</IsTruncated></ListMultipartUploadsResult>`)
+
+	case r.Method == http.MethodPut && uploadID != "":
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		f.uploads[uploadID][number] = readBody(r)
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, number))
+
+	case r.Method == http.MethodPost && uploadID != "":
+		parts := f.uploads[uploadID]
+		numbers := make([]int, 0, len(parts))
+		for n := range parts {
+			numbers = append(numbers, n)
+		}
+		slices.Sort(numbers)
+
+		var data []byte
+		for _, n := range numbers {
+			data = append(data, parts[n]...)
+		}
+		f.objects[key] = data
+		delete(f.uploads, uploadID)
+		delete(f.pending, uploadID)
+		fmt.Fprintf(w, `<CompleteMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><ETag>"done"</ETag></CompleteMultipartUploadResult>`, bucket, key)
+
+	case r.Method == http.MethodDelete && uploadID != "":
+		delete(f.uploads, uploadID)
+		delete(f.pending, uploadID)
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPut:
+		f.objects[key] = readBody(r)
+		f.types[key] = r.Header.Get("Content-Type")
+		f.headers[key] = r.Header.Clone()
+
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				io.WriteString(w, `<Error><Code>NoSuchKey</Code><Message>missing</Message></Error>`)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", f.types[key])
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		storedHeaders(w.Header(), f.headers[key])
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "unsupported", http.StatusNotImplemented)
+	}
+}
+
+func storedHeaders(dst, src http.Header) {
+	for name, values := range src {
+		if strings.HasPrefix(strings.ToLower(name), "x-amz-meta-") {
+			dst[name] = values
+		}
+	}
+
+	var encodings []string
+	for _, encoding := range strings.Split(src.Get("Content-Encoding"), ",") {
+		if encoding = strings.TrimSpace(encoding); encoding != "" && encoding != "aws-chunked" {
+			encodings = append(encodings, encoding)
+		}
+	}
+	if len(encodings) > 0 {
+		dst.Set("Content-Encoding", strings.Join(encodings, ","))
+	}
+}
+
+func readBody(r *http.Request) []byte {
+	data, _ := io.ReadAll(r.Body)
+	if !strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") {
+		return data
+	}
+
+	var out []byte
+	br := bufio.NewReader(bytes.NewReader(data))
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return out
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil || size == 0 {
+			return out
+		}
+		chunk := make([]byte, size)
+		io.ReadFull(br, chunk)
+		out = append(out, chunk...)
+		br.ReadString('\n')
+	}
+}
+
+func newTestS3Backend(t *testing.T, fake *fakeS3, sseMode string) *S3Backend {
+	t.Helper()
+
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	backend, err := NewS3Backend(context.Background(), config.AWSConfig{
+		Region:      "us-east-1",
+		AccessKey:   "test",
+		SecretKey:   "test",
+		S3Bucket:    fake.bucket,
+		S3Endpoint:  srv.URL,
+		S3PathStyle: true,
+		S3SSEMode:   sseMode,
+	}, config.StorageConfig{
+		MaxPresignTTL: time.Hour,
+		Multipart: config.MultipartConfig{
+			Threshold:   6 * MiB,
+			PartSize:    MinPartSize,
+			Concurrency: 3,
+			AbortAfter:  time.Hour,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return backend
+}
+
+func TestS3BackendPathStyleEndpoint(t *testing.T) {
+	fake := newFakeS3("uploads")
+	backend := newTestS3Backend(t, fake, config.SSEModeNone)
+	ctx := context.Background()
+
+	data := []byte("hello from a custom endpoint")
+	if err := backend.Put(ctx, "a/b.txt", bytes.NewReader(data), int64(len(data)), "text/plain"); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	if !bytes.Equal(fake.objects["a/b.txt"], data) {
+		t.Fatalf("fake stored %q", fake.objects["a/b.txt"])
+	}
+
+	obj, err := backend.Get(ctx, "a/b.txt")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	got, _ := io.ReadAll(obj.Body)
+	obj.Body.Close()
+	if !bytes.Equal(got, data) || obj.ContentType != "text/plain" || obj.Size != int64(len(data)) {
+		t.Fatalf("get returned %q, %q, %d", got, obj.ContentType, obj.Size)
+	}
+
+	if ok, err := backend.Exists(ctx, "a/b.txt"); err != nil || !ok {
+		t.Fatalf("exists returned %v, %v", ok, err)
+	}
+	if err := backend.Delete(ctx, "a/b.txt"); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if ok, err := backend.Exists(ctx, "a/b.txt"); err != nil || ok {
+		t.Fatalf("exists after delete returned %v, %v", ok, err)
+	}
+	if _, err := backend.Get(ctx, "a/b.txt"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	url, err := backend.Presign(ctx, http.MethodGet, "a/b.txt", time.Minute)
+	if err != nil {
+		t.Fatalf("presign failed: %v", err)
+	}
+	if !strings.Contains(url, "/uploads/a/b.txt?") {
+		t.Fatalf("presigned url is not path-style: %s", url)
+	}
+}
+
+func TestS3BackendMultipart(t *testing.T) {
+	fake := newFakeS3("uploads")
+	backend := newTestS3Backend(t, fake, config.SSEModeS3)
+
+	data := make([]byte, 3*MinPartSize+123)
+	rand.Read(data)
+
+	if err := backend.Put(context.Background(), "big.bin", bytes.NewReader(data), int64(len(data)), "application/octet-stream"); err != nil {
+		t.Fatalf("multipart put failed: %v", err)
+	}
+
+	if !bytes.Equal(fake.objects["big.bin"], data) {
+		t.Fatalf("reassembled object differs: %d bytes stored", len(fake.objects["big.bin"]))
+	}
+	if len(fake.uploads) != 0 {
+		t.Fatalf("%d multipart uploads left open", len(fake.uploads))
+	}
+	if sse := fake.headers["big.bin"].Get("X-Amz-Server-Side-Encryption"); sse != "AES256" {
+		t.Fatalf("multipart upload created with SSE %q", sse)
+	}
+}
+
+func TestS3BackendPresignPutIncludesSSE(t *testing.T) {
+	fake := newFakeS3("uploads")
+	backend := newTestS3Backend(t, fake, config.SSEModeS3)
+
+	url, err := backend.Presign(context.Background(), http.MethodPut, "a.bin", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(url, "x-amz-server-side-encryption") {
+		t.Fatalf("presigned put does not sign the sse header: %s", url)
+	}
+}
+
+func TestS3BackendPutUnseekableBodyOverHTTP(t *testing.T) {
+	fake := newFakeS3("uploads")
+	backend := newTestS3Backend(t, fake, config.SSEModeNone)
+
+	data := []byte("streamed through a reader that cannot seek")
+	body := io.MultiReader(bytes.NewReader(data[:10]), bytes.NewReader(data[10:]))
+	if _, ok := body.(io.Seeker); ok {
+		t.Fatal("test body must not be seekable")
+	}
+
+	if err := backend.Put(context.Background(), "a/stream.txt", body, int64(len(data)), "text/plain"); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	if !bytes.Equal(fake.objects["a/stream.txt"], data) {
+		t.Fatalf("fake stored %q", fake.objects["a/stream.txt"])
+	}
+}
+
+func TestS3BackendEncryptedPutOverHTTP(t *testing.T) {
+	fake := newFakeS3("uploads")
+	key := make([]byte, 32)
+	rand.Read(key)
+
+	backend, err := NewEncryptedBackend(newTestS3Backend(t, fake, config.SSEModeNone), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	data := make([]byte, 100_000)
+	rand.Read(data)
+	if err := backend.Put(ctx, "a/secret.bin", bytes.NewReader(data), int64(len(data)), "application/octet-stream"); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+
+	obj, err := backend.Get(ctx, "a/secret.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(obj.Body)
+	obj.Body.Close()
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("round trip failed: %v", err)
+	}
+}
+
+func TestS3BackendMultipartJanitorScopedToKeyPrefix(t *testing.T) {
+	fake := newFakeS3("uploads")
+	s3Backend := newTestS3Backend(t, fake, config.SSEModeNone)
+	s3Backend.multipart.AbortInterval = time.Hour
+
+	stale := time.Now().Add(-48 * time.Hour)
+	fake.uploads["1"] = map[int][]byte{}
+	fake.pending["1"] = fakeUpload{key: "uploads/2024/01/01/abandoned", initiated: stale}
+	fake.uploads["2"] = map[int][]byte{}
+	fake.pending["2"] = fakeUpload{key: "other-system/export.csv", initiated: stale}
+	fake.uploads["3"] = map[int][]byte{}
+	fake.pending["3"] = fakeUpload{key: "uploads/2024/01/01/in-progress", initiated: time.Now()}
+
+	key := make([]byte, 32)
+	rand.Read(key)
+	backend, err := NewEncryptedBackend(s3Backend, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		RunMultipartJanitor(ctx, backend, logger.New("test", slog.LevelError))
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		fake.mu.Lock()
+		_, pending := fake.pending["1"]
+		fake.mu.Unlock()
+		if !pending {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stale upload under the key prefix was not aborted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("janitor did not stop when its context was cancelled")
+	}
+
+	if _, ok := fake.pending["2"]; !ok {
+		t.Fatal("janitor aborted an upload outside the service key prefix")
+	}
+	if _, ok := fake.pending["3"]; !ok {
+		t.Fatal("janitor aborted an upload that is not stale yet")
+	}
+}
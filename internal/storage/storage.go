@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/config"
+)
+
+var (
+	ErrNotFound             = errors.New("object not found")
+	ErrPresignNotSupported  = errors.New("presigned urls are not supported by this backend")
+	ErrUnsupportedOperation = errors.New("unsupported presign method")
+)
+
+type Object struct {
+	Body            io.ReadCloser
+	ContentType     string
+	ContentEncoding string
+	DecodedSize     int64
+	Size            int64
+}
+
+type PutOptions struct {
+	ContentEncoding string
+	DecodedSize     int64
+	SHA256          []byte
+}
+
+type PutOption func(*PutOptions)
+
+func WithContentEncoding(encoding string, decodedSize int64) PutOption {
+	return func(o *PutOptions) {
+		o.ContentEncoding = encoding
+		o.DecodedSize = decodedSize
+	}
+}
+
+func WithSHA256(sum []byte) PutOption {
+	return func(o *PutOptions) {
+		o.SHA256 = sum
+	}
+}
+
+func putOptions(opts []PutOption) PutOptions {
+	var o PutOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+type Backend interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string, opts ...PutOption) error
+	Get(ctx context.Context, key string) (*Object, error)
+	Delete(ctx context.Context, key string) error
+	Presign(ctx context.Context, method, key string, expires time.Duration) (string, error)
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+func New(ctx context.Context, cfg *config.Config) (Backend, error) {
+	backend, err := newBackend(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Storage.EncryptionKey != "" {
+		key, err := base64.StdEncoding.DecodeString(cfg.Storage.EncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode encryption master key: %w", err)
+		}
+
+		if backend, err = NewEncryptedBackend(backend, key); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Storage.Compression != config.CompressionNone {
+		backend = NewCompressedBackend(backend, cfg.Storage)
+	}
+
+	return backend, nil
+}
+
+func newBackend(ctx context.Context, cfg *config.Config) (Backend, error) {
+	switch cfg.Storage.Backend {
+	case config.StorageBackendS3:
+		backend, err := NewS3Backend(ctx, cfg.AWS, cfg.Storage)
+		if err != nil {
+			return nil, err
+		}
+
+		return backend, nil
+	case config.StorageBackendLocal:
+		return NewLocalBackend(cfg.Storage.LocalPath)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend %q", cfg.Storage.Backend)
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	maxAdaptivePartSize = 64 * MiB
+
+	throughputSmoothing = 0.3
+)
+
+type ThroughputTracker struct {
+	mu          sync.Mutex
+	bytesPerSec float64
+	target      time.Duration
+}
+
+func NewThroughputTracker(target time.Duration) *ThroughputTracker {
+	return &ThroughputTracker{target: target}
+}
+
+func (t *ThroughputTracker) Observe(n int64, elapsed time.Duration) {
+	if n <= 0 || elapsed <= 0 {
+		return
+	}
+
+	rate := float64(n) / elapsed.Seconds()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.bytesPerSec == 0 {
+		t.bytesPerSec = rate
+		return
+	}
+	t.bytesPerSec = throughputSmoothing*rate + (1-throughputSmoothing)*t.bytesPerSec
+}
+
+func (t *ThroughputTracker) BytesPerSecond() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.bytesPerSec
+}
+
+func (t *ThroughputTracker) PartSize(preferred int64) int64 {
+	rate := t.BytesPerSecond()
+	if rate == 0 {
+		return preferred
+	}
+
+	size := int64(rate*t.target.Seconds()) / MiB * MiB
+
+	return min(max(size, MinPartSize), maxAdaptivePartSize)
+}
@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"context"
+	"io"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/checksum"
+)
+
+func PutVerified(ctx context.Context, backend Backend, key string, body io.Reader, size int64, contentType string, expectedSHA256 []byte, algorithms []string) (checksum.Sums, error) {
+	reader, err := checksum.NewReader(body, algorithms...)
+	if err != nil {
+		return checksum.Sums{}, err
+	}
+
+	reader.Enforce(expectedSHA256, size)
+
+	if err := backend.Put(ctx, key, reader, size, contentType, WithSHA256(expectedSHA256)); err != nil {
+		return checksum.Sums{}, err
+	}
+
+	return reader.Sums(), nil
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/checksum"
+	"github.com/ifaisalabid1/file-upload-service/internal/config"
+	"github.com/ifaisalabid1/file-upload-service/internal/encryption"
+)
+
+func TestPutVerifiedKeepsExistingObjectOnMismatch(t *testing.T) {
+	root := t.TempDir()
+	local, err := NewLocalBackend(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := make([]byte, encryption.KeySize)
+	rand.Read(key)
+	encrypted, err := NewEncryptedBackend(local, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backends := map[string]Backend{
+		"local":      local,
+		"encrypted":  encrypted,
+		"compressed": NewCompressedBackend(local, config.StorageConfig{Compression: config.CompressionGzip, CompressTypes: []string{"text/*"}}),
+	}
+
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			good := []byte("the original, correct object")
+			sum := sha256.Sum256(good)
+
+			if _, err := PutVerified(ctx, backend, name, bytes.NewReader(good), int64(len(good)), "text/plain", sum[:], nil); err != nil {
+				t.Fatal(err)
+			}
+
+			bad := []byte("a corrupted replacement upload")
+			_, err := PutVerified(ctx, backend, name, bytes.NewReader(bad), int64(len(bad)), "text/plain", sum[:], nil)
+			if !errors.Is(err, checksum.ErrMismatch) {
+				t.Fatalf("got %v, want ErrMismatch", err)
+			}
+
+			obj, err := backend.Get(ctx, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(obj.Body)
+			obj.Body.Close()
+			if err != nil || !bytes.Equal(got, good) {
+				t.Fatalf("existing object was replaced or lost: %q, %v", got, err)
+			}
+
+			entries, _ := os.ReadDir(root)
+			for _, entry := range entries {
+				if strings.HasPrefix(entry.Name(), ".upload-") {
+					t.Fatalf("temp file %s left behind", entry.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestPutVerifiedS3(t *testing.T) {
+	fake := newFakeS3("uploads")
+	backend := newTestS3Backend(t, fake, config.SSEModeNone)
+	ctx := context.Background()
+
+	data := []byte("verified upload")
+	sum := sha256.Sum256(data)
+
+	sums, err := PutVerified(ctx, backend, "a/ok.txt", bytes.NewReader(data), int64(len(data)), "text/plain", sum[:], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.headers["a/ok.txt"].Get("X-Amz-Checksum-Sha256"); got != base64.StdEncoding.EncodeToString(sum[:]) {
+		t.Fatalf("x-amz-checksum-sha256 = %q", got)
+	}
+	if sums.SHA256 == "" {
+		t.Fatal("sums not returned")
+	}
+
+	bad := []byte("tampered upload")
+	if _, err := PutVerified(ctx, backend, "a/ok.txt", bytes.NewReader(bad), int64(len(bad)), "text/plain", sum[:], nil); !errors.Is(err, checksum.ErrMismatch) {
+		t.Fatalf("got %v, want ErrMismatch", err)
+	}
+	if !bytes.Equal(fake.objects["a/ok.txt"], data) {
+		t.Fatalf("existing object was overwritten with %q", fake.objects["a/ok.txt"])
+	}
+}
+
+func TestPutVerifiedS3MultipartAbortsOnMismatch(t *testing.T) {
+	fake := newFakeS3("uploads")
+	backend := newTestS3Backend(t, fake, config.SSEModeNone)
+
+	data := make([]byte, 2*MinPartSize)
+	rand.Read(data)
+	sum := sha256.Sum256([]byte("something else"))
+
+	_, err := PutVerified(context.Background(), backend, "big.bin", bytes.NewReader(data), int64(len(data)), "application/octet-stream", sum[:], nil)
+	if !errors.Is(err, checksum.ErrMismatch) {
+		t.Fatalf("got %v, want ErrMismatch", err)
+	}
+	if _, ok := fake.objects["big.bin"]; ok {
+		t.Fatal("multipart upload was completed despite the mismatch")
+	}
+	if len(fake.uploads) != 0 {
+		t.Fatalf("%d multipart uploads left open", len(fake.uploads))
+	}
+}
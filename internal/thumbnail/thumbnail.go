@@ -0,0 +1,124 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/filetype"
+)
+
+const (
+	Prefix = "thumbnails"
+
+	jpegQuality = 85
+)
+
+var ErrUnsupportedImage = errors.New("image cannot be thumbnailed")
+
+type Thumbnail struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+func Generate(data []byte, size int) (*Thumbnail, error) {
+	src, format, err := filetype.DecodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedImage, err)
+	}
+
+	width, height := fit(src.Bounds().Dx(), src.Bounds().Dy(), size)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+
+	contentType := ContentType(format)
+
+	encoded, err := encode(dst, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Thumbnail{
+		Data:        encoded,
+		ContentType: contentType,
+		Width:       width,
+		Height:      height,
+	}, nil
+}
+
+func encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+
+	if contentType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func ContentType(format string) string {
+	switch format {
+	case "png", "gif":
+		return "image/png"
+	default:
+		return "image/jpeg"
+	}
+}
+
+func Key(key string, size int, contentType string) string {
+	ext := ".jpg"
+	if contentType == "image/png" {
+		ext = ".png"
+	}
+
+	base := strings.TrimSuffix(key, path.Ext(key))
+
+	return path.Join(Prefix, strconv.Itoa(size), base+ext)
+}
+
+func Nearest(sizes []int, requested int) int {
+	best := 0
+	for _, size := range sizes {
+		if best == 0 || abs(size-requested) < abs(best-requested) {
+			best = size
+		}
+	}
+
+	return best
+}
+
+func fit(width, height, size int) (int, int) {
+	if width <= size && height <= size {
+		return width, height
+	}
+
+	if width >= height {
+		return size, max(1, height*size/width)
+	}
+
+	return max(1, width*size/height), size
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,184 @@
+package thumbnail
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+
+	"github.com/ifaisalabid1/file-upload-service/internal/filetype"
+)
+
+const (
+	TransformPrefix = "transforms"
+
+	MaxDimension = 4096
+)
+
+const (
+	FitContain = "contain"
+	FitCover   = "cover"
+	FitFill    = "fill"
+)
+
+var (
+	ErrInvalidTransform  = errors.New("invalid transform")
+	ErrUnsupportedFormat = errors.New("unsupported output format")
+)
+
+type Options struct {
+	Width  int
+	Height int
+	Fit    string
+	Format string
+}
+
+func ParseOptions(width, height, fit, format string) (Options, error) {
+	opts := Options{Fit: strings.ToLower(fit), Format: strings.ToLower(format)}
+
+	var err error
+	if width != "" {
+		if opts.Width, err = strconv.Atoi(width); err != nil {
+			return Options{}, fmt.Errorf("%w: width %q", ErrInvalidTransform, width)
+		}
+	}
+	if height != "" {
+		if opts.Height, err = strconv.Atoi(height); err != nil {
+			return Options{}, fmt.Errorf("%w: height %q", ErrInvalidTransform, height)
+		}
+	}
+	if opts.Fit == "" {
+		opts.Fit = FitContain
+	}
+
+	return opts, opts.Validate()
+}
+
+func (o Options) Validate() error {
+	if o.Width < 0 || o.Height < 0 || o.Width > MaxDimension || o.Height > MaxDimension {
+		return fmt.Errorf("%w: dimensions must be between 1 and %d", ErrInvalidTransform, MaxDimension)
+	}
+	if o.Width == 0 && o.Height == 0 {
+		return fmt.Errorf("%w: width or height is required", ErrInvalidTransform)
+	}
+
+	switch o.Fit {
+	case FitContain, FitCover, FitFill:
+	default:
+		return fmt.Errorf("%w: fit must be one of contain, cover, fill", ErrInvalidTransform)
+	}
+
+	switch o.Format {
+	case "", "jpeg", "jpg", "png":
+	case "webp":
+		return fmt.Errorf("%w: webp encoding is not available", ErrUnsupportedFormat)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, o.Format)
+	}
+
+	return nil
+}
+
+func (o Options) Key(key string) string {
+	format := o.Format
+	if format == "jpg" {
+		format = "jpeg"
+	}
+	if format == "" {
+		format = "auto"
+	}
+
+	variant := fmt.Sprintf("w%d_h%d_%s_%s", o.Width, o.Height, o.Fit, format)
+
+	return path.Join(TransformPrefix, variant, key)
+}
+
+func Transform(data []byte, opts Options) (*Thumbnail, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	src, format, err := filetype.DecodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedImage, err)
+	}
+
+	bounds := src.Bounds()
+	width, height := targetSize(bounds.Dx(), bounds.Dy(), opts.Width, opts.Height)
+
+	srcRect := bounds
+	switch opts.Fit {
+	case FitContain:
+		width, height = contain(bounds.Dx(), bounds.Dy(), width, height)
+	case FitCover:
+		srcRect = cover(bounds, width, height)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, srcRect, draw.Src, nil)
+
+	contentType := ContentType(format)
+	switch opts.Format {
+	case "jpeg", "jpg":
+		contentType = "image/jpeg"
+	case "png":
+		contentType = "image/png"
+	}
+
+	encoded, err := encode(dst, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Thumbnail{
+		Data:        encoded,
+		ContentType: contentType,
+		Width:       width,
+		Height:      height,
+	}, nil
+}
+
+func targetSize(srcWidth, srcHeight, width, height int) (int, int) {
+	switch {
+	case width == 0:
+		width = max(1, srcWidth*height/srcHeight)
+	case height == 0:
+		height = max(1, srcHeight*width/srcWidth)
+	default:
+		return width, height
+	}
+
+	if width > MaxDimension || height > MaxDimension {
+		return contain(width, height, MaxDimension, MaxDimension)
+	}
+
+	return width, height
+}
+
+func contain(srcWidth, srcHeight, width, height int) (int, int) {
+	if srcWidth*height > srcHeight*width {
+		return width, max(1, srcHeight*width/srcWidth)
+	}
+
+	return max(1, srcWidth*height/srcHeight), height
+}
+
+func cover(bounds image.Rectangle, width, height int) image.Rectangle {
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	cropWidth, cropHeight := srcWidth, srcHeight
+	if srcWidth*height > srcHeight*width {
+		cropWidth = srcHeight * width / height
+	} else {
+		cropHeight = srcWidth * height / width
+	}
+
+	x := bounds.Min.X + (srcWidth-cropWidth)/2
+	y := bounds.Min.Y + (srcHeight-cropHeight)/2
+
+	return image.Rect(x, y, x+cropWidth, y+cropHeight)
+}